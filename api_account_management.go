@@ -1,87 +1,106 @@
+// Code generated by cmd/gen from cmd/gen/deribit_api.json. DO NOT EDIT.
+
 package deribit
 
 import "github.com/frankrap/deribit-api/models"
 
+// GetAnnouncements retrieves announcements from the last 30 days
 func (c *Client) GetAnnouncements() (result []models.Announcement, err error) {
 	err = c.Call("public/get_announcements", nil, &result)
 	return
 }
 
+// ChangeSubaccountName changes the user name of a subaccount
 func (c *Client) ChangeSubaccountName(params *models.ChangeSubaccountNameParams) (result string, err error) {
 	err = c.Call("private/change_subaccount_name", params, &result)
 	return
 }
 
+// CreateSubaccount creates a new subaccount
 func (c *Client) CreateSubaccount() (result models.Subaccount, err error) {
 	err = c.Call("private/create_subaccount", nil, &result)
 	return
 }
 
+// DisableTfaForSubaccount disables two factor authentication for a subaccount
 func (c *Client) DisableTfaForSubaccount(params *models.DisableTfaForSubaccountParams) (result string, err error) {
 	err = c.Call("private/disable_tfa_for_subaccount", params, &result)
 	return
 }
 
+// GetAccountSummary retrieves the user account summary of a currency
 func (c *Client) GetAccountSummary(params *models.GetAccountSummaryParams) (result models.AccountSummary, err error) {
 	err = c.Call("private/get_account_summary", params, &result)
 	return
 }
 
+// GetEmailLanguage retrieves the language used for emails
 func (c *Client) GetEmailLanguage() (result string, err error) {
 	err = c.Call("private/get_email_language", nil, &result)
 	return
 }
 
+// GetNewAnnouncements retrieves the announcements not marked as read
 func (c *Client) GetNewAnnouncements() (result []models.Announcement, err error) {
 	err = c.Call("private/get_new_announcements", nil, &result)
 	return
 }
 
+// GetPosition retrieves the user position of an instrument
 func (c *Client) GetPosition(params *models.GetPositionParams) (result models.Position, err error) {
 	err = c.Call("private/get_position", params, &result)
 	return
 }
 
+// GetPositions retrieves the user positions of a currency
 func (c *Client) GetPositions(params *models.GetPositionsParams) (result []models.Position, err error) {
 	err = c.Call("private/get_positions", params, &result)
 	return
 }
 
+// GetSubaccounts retrieves the information of the subaccounts and optionally their portfolios
 func (c *Client) GetSubaccounts(params *models.GetSubaccountsParams) (result []models.Subaccount, err error) {
 	err = c.Call("private/get_subaccounts", params, &result)
 	return
 }
 
+// GetSubaccountsDetails retrieves the open orders and positions of the subaccounts in a currency
 func (c *Client) GetSubaccountsDetails(params *models.GetSubaccountsDetailsParams) (result []models.SubaccountsDetails, err error) {
 	err = c.Call("private/get_subaccounts_details", params, &result)
 	return
 }
 
+// SetAnnouncementAsRead marks an announcement as read so it is no longer returned by GetNewAnnouncements
 func (c *Client) SetAnnouncementAsRead(params *models.SetAnnouncementAsReadParams) (result string, err error) {
 	err = c.Call("private/set_announcement_as_read", params, &result)
 	return
 }
 
+// SetEmailForSubaccount assigns an email address to a subaccount
 func (c *Client) SetEmailForSubaccount(params *models.SetEmailForSubaccountParams) (result string, err error) {
 	err = c.Call("private/set_email_for_subaccount", params, &result)
 	return
 }
 
+// SetEmailLanguage changes the language used for emails
 func (c *Client) SetEmailLanguage(params *models.SetEmailLanguageParams) (result string, err error) {
 	err = c.Call("private/set_email_language", params, &result)
 	return
 }
 
+// SetPasswordForSubaccount sets the password of a subaccount
 func (c *Client) SetPasswordForSubaccount(params *models.SetPasswordForSubaccountParams) (result string, err error) {
 	err = c.Call("private/set_password_for_subaccount", params, &result)
 	return
 }
 
+// ToggleNotificationsFromSubaccount enables or disables the notifications sent by a subaccount
 func (c *Client) ToggleNotificationsFromSubaccount(params *models.ToggleNotificationsFromSubaccountParams) (result string, err error) {
 	err = c.Call("private/toggle_notifications_from_subaccount", params, &result)
 	return
 }
 
+// ToggleSubaccountLogin enables or disables the login of a subaccount
 func (c *Client) ToggleSubaccountLogin(params *models.ToggleSubaccountLoginParams) (result string, err error) {
 	err = c.Call("private/toggle_subaccount_login", params, &result)
 	return
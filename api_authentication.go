@@ -15,6 +15,9 @@ func (c *Client) Auth(apiKey string, secretKey string) (err error) {
 	}
 	c.auth.token = result.AccessToken
 	c.auth.refresh = result.RefreshToken
+	if t, ok := c.transport.(tokenSetter); ok {
+		t.SetToken(result.AccessToken)
+	}
 	return
 }
 
@@ -1,92 +1,112 @@
+// Code generated by cmd/gen from cmd/gen/deribit_api.json. DO NOT EDIT.
+
 package deribit
 
 import "github.com/frankrap/deribit-api/models"
 
+// GetBookSummaryByCurrency retrieves the summary information such as open interest and 24h volume of the instruments of a currency, optionally filtered by kind
 func (c *Client) GetBookSummaryByCurrency(params *models.GetBookSummaryByCurrencyParams) (result []models.BookSummary, err error) {
 	err = c.Call("public/get_book_summary_by_currency", params, &result)
 	return
 }
 
+// GetBookSummaryByInstrument retrieves the summary information such as open interest and 24h volume of an instrument
 func (c *Client) GetBookSummaryByInstrument(params *models.GetBookSummaryByInstrumentParams) (result []models.BookSummary, err error) {
 	err = c.Call("public/get_book_summary_by_instrument", params, &result)
 	return
 }
 
+// GetContractSize retrieves the contract size of an instrument
 func (c *Client) GetContractSize(params *models.GetContractSizeParams) (result models.GetContractSizeResponse, err error) {
 	err = c.Call("public/get_contract_size", params, &result)
 	return
 }
 
+// GetCurrencies retrieves all cryptocurrencies supported by the API
 func (c *Client) GetCurrencies() (result []models.Currency, err error) {
 	err = c.Call("public/get_currencies", nil, &result)
 	return
 }
 
+// GetFundingChartData retrieves the funding rate chart data of a perpetual
 func (c *Client) GetFundingChartData(params *models.GetFundingChartDataParams) (result models.GetFundingChartDataResponse, err error) {
 	err = c.Call("public/get_funding_chart_data", params, &result)
 	return
 }
 
+// GetHistoricalVolatility retrieves the historical volatility of a currency
 func (c *Client) GetHistoricalVolatility(params *models.GetHistoricalVolatilityParams) (result models.GetHistoricalVolatilityResponse, err error) {
 	err = c.Call("public/get_historical_volatility", params, &result)
 	return
 }
 
+// GetIndex retrieves the current index price of a currency
 func (c *Client) GetIndex(params *models.GetIndexParams) (result models.GetIndexResponse, err error) {
 	err = c.Call("public/get_index", params, &result)
 	return
 }
 
+// GetInstruments retrieves the available instruments of a currency, optionally filtered by kind
 func (c *Client) GetInstruments(params *models.GetInstrumentsParams) (result []models.Instrument, err error) {
 	err = c.Call("public/get_instruments", params, &result)
 	return
 }
 
+// GetLastSettlementsByCurrency retrieves the historical settlement, delivery and bankruptcy events of a currency
 func (c *Client) GetLastSettlementsByCurrency(params *models.GetLastSettlementsByCurrencyParams) (result models.GetLastSettlementsResponse, err error) {
 	err = c.Call("public/get_last_settlements_by_currency", params, &result)
 	return
 }
 
+// GetLastSettlementsByInstrument retrieves the historical settlement, delivery and bankruptcy events of an instrument
 func (c *Client) GetLastSettlementsByInstrument(params *models.GetLastSettlementsByInstrumentParams) (result models.GetLastSettlementsResponse, err error) {
 	err = c.Call("public/get_last_settlements_by_instrument", params, &result)
 	return
 }
 
+// GetLastTradesByCurrency retrieves the latest trades of the instruments of a currency
 func (c *Client) GetLastTradesByCurrency(params *models.GetLastTradesByCurrencyParams) (result models.GetLastTradesResponse, err error) {
 	err = c.Call("public/get_last_trades_by_currency", params, &result)
 	return
 }
 
+// GetLastTradesByCurrencyAndTime retrieves the trades of the instruments of a currency within a time range
 func (c *Client) GetLastTradesByCurrencyAndTime(params *models.GetLastTradesByCurrencyAndTimeParams) (result models.GetLastTradesResponse, err error) {
 	err = c.Call("public/get_last_trades_by_currency_and_time", params, &result)
 	return
 }
 
+// GetLastTradesByInstrument retrieves the latest trades of an instrument
 func (c *Client) GetLastTradesByInstrument(params *models.GetLastTradesByInstrumentParams) (result models.GetLastTradesResponse, err error) {
 	err = c.Call("public/get_last_trades_by_instrument", params, &result)
 	return
 }
 
+// GetLastTradesByInstrumentAndTime retrieves the trades of an instrument within a time range
 func (c *Client) GetLastTradesByInstrumentAndTime(params *models.GetLastTradesByInstrumentAndTimeParams) (result models.GetLastTradesResponse, err error) {
 	err = c.Call("public/get_last_trades_by_instrument_and_time", params, &result)
 	return
 }
 
+// GetOrderBook retrieves the order book of an instrument with its market data
 func (c *Client) GetOrderBook(params *models.GetOrderBookParams) (result models.GetOrderBookResponse, err error) {
 	err = c.Call("public/get_order_book", params, &result)
 	return
 }
 
+// GetTradeVolumes retrieves the aggregated 24h trade volumes of each currency
 func (c *Client) GetTradeVolumes() (result models.GetTradeVolumesResponse, err error) {
 	err = c.Call("public/get_trade_volumes", nil, &result)
 	return
 }
 
+// GetTradingviewChartData retrieves the OHLCV candles of an instrument for a resolution and time range
 func (c *Client) GetTradingviewChartData(params *models.GetTradingviewChartDataParams) (result models.GetTradingviewChartDataResponse, err error) {
 	err = c.Call("public/get_tradingview_chart_data", params, &result)
 	return
 }
 
+// Ticker retrieves the ticker of an instrument
 func (c *Client) Ticker(params *models.TickerParams) (result models.TickerResponse, err error) {
 	err = c.Call("public/ticker", params, &result)
 	return
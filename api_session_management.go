@@ -1,24 +1,28 @@
+// Code generated by cmd/gen from cmd/gen/deribit_api.json. DO NOT EDIT.
+
 package deribit
 
-import (
-	"github.com/frankrap/deribit-api/models"
-)
+import "github.com/frankrap/deribit-api/models"
 
+// SetHeartbeat signals the server to send heartbeats at an interval, the connection is closed when they are not answered
 func (c *Client) SetHeartbeat(params *models.SetHeartbeatParams) (result string, err error) {
 	err = c.Call("public/set_heartbeat", params, &result)
 	return
 }
 
+// DisableHeartbeat stops the heartbeats of the connection
 func (c *Client) DisableHeartbeat() (result string, err error) {
 	err = c.Call("public/disable_heartbeat", nil, &result)
 	return
 }
 
+// EnableCancelOnDisconnect enables cancelling all orders of the connection when it is closed
 func (c *Client) EnableCancelOnDisconnect() (result string, err error) {
 	err = c.Call("private/enable_cancel_on_disconnect", nil, &result)
 	return
 }
 
+// DisableCancelOnDisconnect disables cancelling all orders of the connection when it is closed
 func (c *Client) DisableCancelOnDisconnect() (result string, err error) {
 	err = c.Call("private/disable_cancel_on_disconnect", nil, &result)
 	return
@@ -1,22 +1,28 @@
+// Code generated by cmd/gen from cmd/gen/deribit_api.json. DO NOT EDIT.
+
 package deribit
 
 import "github.com/frankrap/deribit-api/models"
 
+// PublicSubscribe subscribes to public channels, prefer Subscribe which also resubscribes on reconnect
 func (c *Client) PublicSubscribe(params *models.SubscribeParams) (result models.SubscribeResponse, err error) {
 	err = c.Call("public/subscribe", params, &result)
 	return
 }
 
+// PublicUnsubscribe unsubscribes from public channels
 func (c *Client) PublicUnsubscribe(params *models.UnsubscribeParams) (result models.UnsubscribeResponse, err error) {
 	err = c.Call("public/unsubscribe", params, &result)
 	return
 }
 
+// PrivateSubscribe subscribes to public and private channels, prefer Subscribe which also resubscribes on reconnect
 func (c *Client) PrivateSubscribe(params *models.SubscribeParams) (result models.SubscribeResponse, err error) {
 	err = c.Call("private/subscribe", params, &result)
 	return
 }
 
+// PrivateUnsubscribe unsubscribes from public and private channels
 func (c *Client) PrivateUnsubscribe(params *models.UnsubscribeParams) (result models.UnsubscribeResponse, err error) {
 	err = c.Call("private/unsubscribe", params, &result)
 	return
@@ -1,17 +1,22 @@
+// Code generated by cmd/gen from cmd/gen/deribit_api.json. DO NOT EDIT.
+
 package deribit
 
 import "github.com/frankrap/deribit-api/models"
 
+// GetTime retrieves the current server time in milliseconds
 func (c *Client) GetTime() (result int64, err error) {
 	err = c.Call("public/get_time", nil, &result)
 	return
 }
 
+// Hello introduces the client software to the server and returns the API version
 func (c *Client) Hello(params *models.HelloParams) (result models.HelloResponse, err error) {
 	err = c.Call("public/hello", params, &result)
 	return
 }
 
+// Test tests the connection and returns the API version
 func (c *Client) Test() (result models.TestResponse, err error) {
 	err = c.Call("public/test", nil, &result)
 	return
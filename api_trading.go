@@ -1,119 +1,142 @@
+// Code generated by cmd/gen from cmd/gen/deribit_api.json. DO NOT EDIT.
+
 package deribit
 
-import (
-	"github.com/frankrap/deribit-api/models"
-)
+import "github.com/frankrap/deribit-api/models"
 
+// Buy places a buy order
 func (c *Client) Buy(params *models.BuyParams) (result models.BuyResponse, err error) {
 	err = c.Call("private/buy", params, &result)
 	return
 }
 
+// Sell places a sell order
 func (c *Client) Sell(params *models.SellParams) (result models.SellResponse, err error) {
 	err = c.Call("private/sell", params, &result)
 	return
 }
 
+// Edit changes the price, amount and options of an open order
 func (c *Client) Edit(params *models.EditParams) (result models.EditResponse, err error) {
 	err = c.Call("private/edit", params, &result)
 	return
 }
 
+// Cancel cancels an order
 func (c *Client) Cancel(params *models.CancelParams) (result models.Order, err error) {
 	err = c.Call("private/cancel", params, &result)
 	return
 }
 
+// CancelAll cancels all orders of all currencies and instruments
 func (c *Client) CancelAll() (result string, err error) {
 	err = c.Call("private/cancel_all", nil, &result)
 	return
 }
 
+// CancelAllByCurrency cancels all orders of a currency, optionally filtered by kind and type
 func (c *Client) CancelAllByCurrency(params *models.CancelAllByCurrencyParams) (result string, err error) {
 	err = c.Call("private/cancel_all_by_currency", params, &result)
 	return
 }
 
+// CancelAllByInstrument cancels all orders of an instrument, optionally filtered by type
 func (c *Client) CancelAllByInstrument(params *models.CancelAllByInstrumentParams) (result string, err error) {
 	err = c.Call("private/cancel_all_by_instrument", params, &result)
 	return
 }
 
+// ClosePosition closes the position of an instrument with a limit or market order
 func (c *Client) ClosePosition(params *models.ClosePositionParams) (result models.ClosePositionResponse, err error) {
 	err = c.Call("private/close_position", params, &result)
 	return
 }
 
+// GetMargins retrieves the margins required for an order of an instrument, amount and price
 func (c *Client) GetMargins(params *models.GetMarginsParams) (result models.GetMarginsResponse, err error) {
 	err = c.Call("private/get_margins", params, &result)
 	return
 }
 
+// GetOpenOrdersByCurrency retrieves the open orders of a currency
 func (c *Client) GetOpenOrdersByCurrency(params *models.GetOpenOrdersByCurrencyParams) (result []models.Order, err error) {
 	err = c.Call("private/get_open_orders_by_currency", params, &result)
 	return
 }
 
+// GetOpenOrdersByInstrument retrieves the open orders of an instrument
 func (c *Client) GetOpenOrdersByInstrument(params *models.GetOpenOrdersByInstrumentParams) (result []models.Order, err error) {
 	err = c.Call("private/get_open_orders_by_instrument", params, &result)
 	return
 }
 
+// GetOrderHistoryByCurrency retrieves the order history of a currency
 func (c *Client) GetOrderHistoryByCurrency(params *models.GetOrderHistoryByCurrencyParams) (result []models.Order, err error) {
 	err = c.Call("private/get_order_history_by_currency", params, &result)
 	return
 }
 
+// GetOrderHistoryByInstrument retrieves the order history of an instrument
 func (c *Client) GetOrderHistoryByInstrument(params *models.GetOrderHistoryByInstrumentParams) (result []models.Order, err error) {
 	err = c.Call("private/get_order_history_by_instrument", params, &result)
 	return
 }
 
+// GetOrderMarginByIDs retrieves the initial margins of orders
 func (c *Client) GetOrderMarginByIDs(params *models.GetOrderMarginByIDsParams) (result models.GetOrderMarginByIDsResponse, err error) {
 	err = c.Call("private/get_order_margin_by_ids", params, &result)
 	return
 }
 
+// GetOrderState retrieves the state of an order
 func (c *Client) GetOrderState(params *models.GetOrderStateParams) (result models.Order, err error) {
 	err = c.Call("private/get_order_state", params, &result)
 	return
 }
 
+// GetStopOrderHistory retrieves the history of the stop orders of a currency
 func (c *Client) GetStopOrderHistory(params *models.GetStopOrderHistoryParams) (result models.GetStopOrderHistoryResponse, err error) {
 	err = c.Call("private/get_stop_order_history", params, &result)
 	return
 }
 
+// GetUserTradesByCurrency retrieves the latest user trades of a currency
 func (c *Client) GetUserTradesByCurrency(params *models.GetUserTradesByCurrencyParams) (result models.GetUserTradesResponse, err error) {
 	err = c.Call("private/get_user_trades_by_currency", params, &result)
 	return
 }
 
+// GetUserTradesByCurrencyAndTime retrieves the user trades of a currency within a time range
 func (c *Client) GetUserTradesByCurrencyAndTime(params *models.GetUserTradesByCurrencyAndTimeParams) (result models.GetUserTradesResponse, err error) {
 	err = c.Call("private/get_user_trades_by_currency_and_time", params, &result)
 	return
 }
 
+// GetUserTradesByInstrument retrieves the latest user trades of an instrument
 func (c *Client) GetUserTradesByInstrument(params *models.GetUserTradesByInstrumentParams) (result models.GetUserTradesResponse, err error) {
 	err = c.Call("private/get_user_trades_by_instrument", params, &result)
 	return
 }
 
+// GetUserTradesByInstrumentAndTime retrieves the user trades of an instrument within a time range
 func (c *Client) GetUserTradesByInstrumentAndTime(params *models.GetUserTradesByInstrumentAndTimeParams) (result models.GetUserTradesResponse, err error) {
 	err = c.Call("private/get_user_trades_by_instrument_and_time", params, &result)
 	return
 }
 
+// GetUserTradesByOrder retrieves the user trades of an order
 func (c *Client) GetUserTradesByOrder(params *models.GetUserTradesByOrderParams) (result models.GetUserTradesResponse, err error) {
 	err = c.Call("private/get_user_trades_by_order", params, &result)
 	return
 }
 
+// GetSettlementHistoryByInstrument retrieves the settlement history of the user for an instrument
 func (c *Client) GetSettlementHistoryByInstrument(params *models.GetSettlementHistoryByInstrumentParams) (result models.GetSettlementHistoryResponse, err error) {
 	err = c.Call("private/get_settlement_history_by_instrument", params, &result)
 	return
 }
 
+// GetSettlementHistoryByCurrency retrieves the settlement history of the user for a currency
 func (c *Client) GetSettlementHistoryByCurrency(params *models.GetSettlementHistoryByCurrencyParams) (result models.GetSettlementHistoryResponse, err error) {
 	err = c.Call("private/get_settlement_history_by_currency", params, &result)
 	return
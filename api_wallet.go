@@ -1,42 +1,52 @@
+// Code generated by cmd/gen from cmd/gen/deribit_api.json. DO NOT EDIT.
+
 package deribit
 
 import "github.com/frankrap/deribit-api/models"
 
+// CancelTransferByID cancels a transfer
 func (c *Client) CancelTransferByID(params *models.CancelTransferByIDParams) (result models.Transfer, err error) {
 	err = c.Call("private/cancel_transfer_by_id", params, &result)
 	return
 }
 
+// CancelWithdrawal cancels a withdrawal
 func (c *Client) CancelWithdrawal(params *models.CancelWithdrawalParams) (result models.Withdrawal, err error) {
 	err = c.Call("private/cancel_withdrawal", params, &result)
 	return
 }
 
+// CreateDepositAddress creates a deposit address of a currency
 func (c *Client) CreateDepositAddress(params *models.CreateDepositAddressParams) (result models.DepositAddress, err error) {
 	err = c.Call("private/create_deposit_address", params, &result)
 	return
 }
 
+// GetCurrentDepositAddress retrieves the current deposit address of a currency
 func (c *Client) GetCurrentDepositAddress(params *models.GetCurrentDepositAddressParams) (result models.DepositAddress, err error) {
 	err = c.Call("private/get_current_deposit_address", params, &result)
 	return
 }
 
+// GetDeposits retrieves the deposits of a currency
 func (c *Client) GetDeposits(params *models.GetDepositsParams) (result models.GetDepositsResponse, err error) {
 	err = c.Call("private/get_deposits", params, &result)
 	return
 }
 
+// GetTransfers retrieves the transfers of a currency
 func (c *Client) GetTransfers(params *models.GetTransfersParams) (result models.GetTransfersResponse, err error) {
 	err = c.Call("private/get_transfers", params, &result)
 	return
 }
 
+// GetWithdrawals retrieves the withdrawals of a currency
 func (c *Client) GetWithdrawals(params *models.GetWithdrawalsParams) (result []models.Withdrawal, err error) {
 	err = c.Call("private/get_withdrawals", params, &result)
 	return
 }
 
+// Withdraw creates a withdrawal to a withdrawal address
 func (c *Client) Withdraw(params *models.WithdrawParams) (result models.Withdrawal, err error) {
 	err = c.Call("private/withdraw", params, &result)
 	return
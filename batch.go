@@ -0,0 +1,100 @@
+package deribit
+
+import (
+	"github.com/frankrap/deribit-api/models"
+	"sync"
+)
+
+// DefaultBatchConcurrency is the number of calls kept in flight when a
+// batch is issued without an explicit limit
+const DefaultBatchConcurrency = 10
+
+// BatchCall is a single JSONRPC call issued as part of a batch.
+// Result must be a pointer the response is decoded into, Err holds
+// the error of this call once the batch has completed.
+type BatchCall struct {
+	Method string
+	Params interface{}
+	Result interface{}
+	Err    error
+}
+
+// CallBatch issues all calls concurrently over the connection, keeping at most
+// maxInFlight requests outstanding, and returns once every call has completed.
+// Errors are reported per call in BatchCall.Err.
+// Each call must use its own params value.
+func (c *Client) CallBatch(calls []*BatchCall, maxInFlight int) {
+	if maxInFlight <= 0 {
+		maxInFlight = DefaultBatchConcurrency
+	}
+	sem := make(chan struct{}, maxInFlight)
+	var wg sync.WaitGroup
+	for _, call := range calls {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(call *BatchCall) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			call.Err = c.Call(call.Method, call.Params, call.Result)
+		}(call)
+	}
+	wg.Wait()
+}
+
+// GetOrderStates fetches the state of every order in orderIDs, results and
+// errors are returned in the same order as orderIDs
+func (c *Client) GetOrderStates(orderIDs []string, maxInFlight int) (result []models.Order, errs []error) {
+	result = make([]models.Order, len(orderIDs))
+	calls := make([]*BatchCall, len(orderIDs))
+	for i, id := range orderIDs {
+		calls[i] = &BatchCall{
+			Method: "private/get_order_state",
+			Params: &models.GetOrderStateParams{OrderID: id},
+			Result: &result[i],
+		}
+	}
+	c.CallBatch(calls, maxInFlight)
+	return result, batchErrors(calls)
+}
+
+// Tickers fetches the ticker of every instrument in instrumentNames, results and
+// errors are returned in the same order as instrumentNames
+func (c *Client) Tickers(instrumentNames []string, maxInFlight int) (result []models.TickerResponse, errs []error) {
+	result = make([]models.TickerResponse, len(instrumentNames))
+	calls := make([]*BatchCall, len(instrumentNames))
+	for i, name := range instrumentNames {
+		calls[i] = &BatchCall{
+			Method: "public/ticker",
+			Params: &models.TickerParams{InstrumentName: name},
+			Result: &result[i],
+		}
+	}
+	c.CallBatch(calls, maxInFlight)
+	return result, batchErrors(calls)
+}
+
+// GetOrderBooks fetches the order book of every instrument in instrumentNames, results and
+// errors are returned in the same order as instrumentNames
+func (c *Client) GetOrderBooks(instrumentNames []string, depth int, maxInFlight int) (result []models.GetOrderBookResponse, errs []error) {
+	result = make([]models.GetOrderBookResponse, len(instrumentNames))
+	calls := make([]*BatchCall, len(instrumentNames))
+	for i, name := range instrumentNames {
+		calls[i] = &BatchCall{
+			Method: "public/get_order_book",
+			Params: &models.GetOrderBookParams{InstrumentName: name, Depth: depth},
+			Result: &result[i],
+		}
+	}
+	c.CallBatch(calls, maxInFlight)
+	return result, batchErrors(calls)
+}
+
+func batchErrors(calls []*BatchCall) []error {
+	errs := make([]error, len(calls))
+	for i, call := range calls {
+		errs[i] = call.Err
+	}
+	return errs
+}
@@ -0,0 +1,297 @@
+package candles
+
+import (
+	"context"
+	"github.com/chuckpreslar/emission"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/models"
+	"sync"
+	"time"
+)
+
+// Events emitted by a Builder with the *Candle
+const (
+	// EventUpdate is emitted when a trade updates the current candle
+	EventUpdate = "update"
+	// EventClose is emitted when a candle closes
+	EventClose = "close"
+)
+
+// DefaultMaxCandles is the number of closed candles a Builder keeps by default
+const DefaultMaxCandles = 10000
+
+// closeDelay is how long after the end of its period a candle without trades of the
+// next period is closed, so trades delivered late still count
+const closeDelay = time.Second
+
+// Builder keeps the candles of an instrument up to date from its trades.
+//
+// The current candle is closed by the first trade of a later period, or by
+// the clock once the period is over. Periods without trades close as flat
+// candles with no volume. Trades are counted once by trade_seq.
+//
+// Volume is in the base currency, like the volume of the chart data: the
+// USD amounts of inverse futures trades are converted at the trade price.
+type Builder struct {
+	// MaxCandles is the number of closed candles kept, the oldest are dropped first.
+	// Defaults to DefaultMaxCandles, 0 keeps every candle.
+	MaxCandles int
+
+	client     *deribit.Client
+	instrument string
+	inverse    bool
+	period     time.Duration
+	emitter    *emission.Emitter
+
+	mu        sync.Mutex
+	closed    []Candle
+	current   *Candle
+	empty     bool // the current candle has no trade yet
+	tradeSeq  int  // the last trade counted
+	stop      chan struct{}
+	listening bool
+	now       func() time.Time
+}
+
+// NewBuilder creates a Builder of candles of period, any multiple of a minute
+func NewBuilder(client *deribit.Client, instrument string, period time.Duration) (*Builder, error) {
+	if _, _, err := baseResolution(period); err != nil {
+		return nil, err
+	}
+	id, err := models.ParseInstrumentID(instrument)
+	return &Builder{
+		MaxCandles: DefaultMaxCandles,
+		client:     client,
+		instrument: instrument,
+		inverse:    err == nil && id.Inverse(),
+		period:     period,
+		emitter:    emission.NewEmitter(),
+		now:        time.Now,
+	}, nil
+}
+
+// On adds a listener func(*Candle) for EventUpdate or EventClose
+func (b *Builder) On(event interface{}, listener interface{}) *emission.Emitter {
+	return b.emitter.On(event, listener)
+}
+
+// Off removes a listener
+func (b *Builder) Off(event interface{}, listener interface{}) *emission.Emitter {
+	return b.emitter.Off(event, listener)
+}
+
+// Backfill fetches the closed candles from start until now from the chart data
+// and the current candle from the trades of its period
+func (b *Builder) Backfill(start time.Time) error {
+	now := b.now()
+	open := openTime(millis(now), b.period)
+	candles, err := Fetch(b.client, b.instrument, b.period, start, msTime(open-1))
+	if err != nil {
+		return err
+	}
+	for len(candles) > 0 && candles[len(candles)-1].Timestamp >= open {
+		candles = candles[:len(candles)-1]
+	}
+	var trades []models.Trade
+	it := b.client.TradesIterator(context.Background(), b.instrument, msTime(open), now)
+	for it.Next() {
+		trades = append(trades, it.Trade())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed, b.current, b.empty, b.tradeSeq = candles, nil, false, 0
+	b.trim()
+	if n := len(candles); n > 0 {
+		last := candles[n-1]
+		if ms := int64(b.period / time.Millisecond); last.Timestamp+ms == open {
+			b.current = &Candle{Timestamp: open, Open: last.Close, High: last.Close, Low: last.Close, Close: last.Close}
+			b.empty = true
+		}
+	}
+	for _, trade := range trades {
+		b.addTrade(trade)
+	}
+	return nil
+}
+
+// Start subscribes to the trades of the instrument and closes candles on time until Stop
+func (b *Builder) Start() {
+	b.mu.Lock()
+	if b.stop != nil {
+		b.mu.Unlock()
+		return
+	}
+	b.stop = make(chan struct{})
+	stop := b.stop
+	listening := b.listening
+	b.listening = true
+	b.mu.Unlock()
+
+	if !listening {
+		// emission removes listeners by code pointer, so the listener stays
+		// registered and ignores trades while stopped
+		channel := "trades." + b.instrument + ".raw"
+		b.client.On(channel, b.onTrades)
+		b.client.Subscribe([]string{channel})
+	}
+	go b.closeOnTime(stop)
+}
+
+// Stop stops updating the candles from trades and the clock
+func (b *Builder) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stop == nil {
+		return
+	}
+	close(b.stop)
+	b.stop = nil
+}
+
+func (b *Builder) onTrades(e *models.TradesNotification) {
+	b.mu.Lock()
+	running := b.stop != nil
+	b.mu.Unlock()
+	if !running {
+		return
+	}
+	for _, trade := range *e {
+		b.AddTrade(trade)
+	}
+}
+
+func (b *Builder) closeOnTime(stop chan struct{}) {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			b.CloseUntil(b.now().Add(-closeDelay))
+		case <-stop:
+			return
+		}
+	}
+}
+
+// AddTrade updates the candles with trade, trades with a trade_seq already counted are ignored
+func (b *Builder) AddTrade(trade models.Trade) {
+	if trade.InstrumentName != "" && trade.InstrumentName != b.instrument {
+		return
+	}
+	b.mu.Lock()
+	closed, updated := b.addTrade(trade)
+	b.mu.Unlock()
+
+	b.emitClosed(closed)
+	if updated != nil {
+		b.emitter.Emit(EventUpdate, updated)
+	}
+}
+
+// addTrade adds trade to the current candle, returning the candles it closed and
+// the updated candle, nil for late and duplicate trades
+func (b *Builder) addTrade(trade models.Trade) ([]Candle, *Candle) {
+	if trade.TradeSeq != 0 {
+		if trade.TradeSeq <= b.tradeSeq {
+			return nil, nil
+		}
+		b.tradeSeq = trade.TradeSeq
+	}
+	closed := b.closeUntil(trade.Timestamp)
+	t := openTime(trade.Timestamp, b.period)
+	if b.current == nil {
+		b.current = &Candle{Timestamp: t, Open: trade.Price, High: trade.Price, Low: trade.Price}
+	}
+	c := b.current
+	if t < c.Timestamp {
+		// late trade of a closed candle
+		return closed, nil
+	}
+	if b.empty {
+		c.Open, c.High, c.Low = trade.Price, trade.Price, trade.Price
+		b.empty = false
+	}
+	if trade.Price > c.High {
+		c.High = trade.Price
+	}
+	if trade.Price < c.Low {
+		c.Low = trade.Price
+	}
+	c.Close = trade.Price
+	c.Volume += b.volume(&trade)
+	updated := *c
+	return closed, &updated
+}
+
+// volume returns the amount of trade in the base currency
+func (b *Builder) volume(trade *models.Trade) float64 {
+	if b.inverse && trade.Price > 0 {
+		return trade.Amount / trade.Price
+	}
+	return trade.Amount
+}
+
+// CloseUntil closes the candles whose period ended by now
+func (b *Builder) CloseUntil(now time.Time) {
+	b.mu.Lock()
+	closed := b.closeUntil(millis(now))
+	b.mu.Unlock()
+	b.emitClosed(closed)
+}
+
+// closeUntil closes the candles before the period of timestamp, filling periods without trades
+func (b *Builder) closeUntil(timestamp int64) []Candle {
+	if b.current == nil {
+		return nil
+	}
+	t := openTime(timestamp, b.period)
+	ms := int64(b.period / time.Millisecond)
+	var closed []Candle
+	for b.current.Timestamp < t {
+		c := *b.current
+		closed = append(closed, c)
+		b.closed = append(b.closed, c)
+		b.current = &Candle{Timestamp: c.Timestamp + ms, Open: c.Close, High: c.Close, Low: c.Close, Close: c.Close}
+		b.empty = true
+	}
+	b.trim()
+	return closed
+}
+
+// trim drops the oldest closed candles beyond MaxCandles
+func (b *Builder) trim() {
+	if b.MaxCandles > 0 && len(b.closed) > b.MaxCandles {
+		b.closed = append([]Candle(nil), b.closed[len(b.closed)-b.MaxCandles:]...)
+	}
+}
+
+func (b *Builder) emitClosed(closed []Candle) {
+	for i := range closed {
+		b.emitter.Emit(EventClose, &closed[i])
+	}
+}
+
+// Candles returns the closed candles, at most MaxCandles
+func (b *Builder) Candles() []Candle {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Candle(nil), b.closed...)
+}
+
+// Current returns the current candle, if any
+func (b *Builder) Current() (Candle, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.current == nil {
+		return Candle{}, false
+	}
+	return *b.current, true
+}
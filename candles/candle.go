@@ -0,0 +1,113 @@
+// Package candles builds OHLCV candles from TradingView chart data and live trades.
+package candles
+
+import (
+	"fmt"
+	"github.com/frankrap/deribit-api/models"
+	"time"
+)
+
+// Candle is an OHLCV candle, Timestamp is its open time in milliseconds
+type Candle struct {
+	Timestamp int64   `json:"timestamp"`
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
+}
+
+// Time returns the open time of the candle
+func (c *Candle) Time() time.Time {
+	return time.Unix(0, c.Timestamp*int64(time.Millisecond))
+}
+
+// resolutions are the resolutions of public/get_tradingview_chart_data, longest first
+var resolutions = []struct {
+	name   string
+	period time.Duration
+}{
+	{"1D", 24 * time.Hour},
+	{"720", 720 * time.Minute},
+	{"360", 360 * time.Minute},
+	{"180", 180 * time.Minute},
+	{"120", 120 * time.Minute},
+	{"60", 60 * time.Minute},
+	{"30", 30 * time.Minute},
+	{"15", 15 * time.Minute},
+	{"10", 10 * time.Minute},
+	{"5", 5 * time.Minute},
+	{"3", 3 * time.Minute},
+	{"1", time.Minute},
+}
+
+// Resolution returns the chart data resolution of period, e.g. "60" for an hour
+func Resolution(period time.Duration) (string, error) {
+	for _, r := range resolutions {
+		if r.period == period {
+			return r.name, nil
+		}
+	}
+	return "", fmt.Errorf("no resolution of %v", period)
+}
+
+// baseResolution returns the longest resolution period is a multiple of
+func baseResolution(period time.Duration) (string, time.Duration, error) {
+	for _, r := range resolutions {
+		if period >= r.period && period%r.period == 0 {
+			return r.name, r.period, nil
+		}
+	}
+	return "", 0, fmt.Errorf("period %v is not a multiple of a minute", period)
+}
+
+// FromChartData converts the parallel slices of chart data to candles
+func FromChartData(data *models.GetTradingviewChartDataResponse) []Candle {
+	n := len(data.Ticks)
+	for _, s := range [][]float64{data.Open, data.High, data.Low, data.Close, data.Volume} {
+		if len(s) < n {
+			n = len(s)
+		}
+	}
+	candles := make([]Candle, n)
+	for i := range candles {
+		candles[i] = Candle{
+			Timestamp: data.Ticks[i],
+			Open:      data.Open[i],
+			High:      data.High[i],
+			Low:       data.Low[i],
+			Close:     data.Close[i],
+			Volume:    data.Volume[i],
+		}
+	}
+	return candles
+}
+
+// openTime returns the open time in milliseconds of the candle of period containing timestamp
+func openTime(timestamp int64, period time.Duration) int64 {
+	ms := int64(period / time.Millisecond)
+	return timestamp - timestamp%ms
+}
+
+// Aggregate merges candles sorted by time into candles of period
+func Aggregate(candles []Candle, period time.Duration) []Candle {
+	var result []Candle
+	for _, c := range candles {
+		t := openTime(c.Timestamp, period)
+		if len(result) == 0 || result[len(result)-1].Timestamp != t {
+			c.Timestamp = t
+			result = append(result, c)
+			continue
+		}
+		last := &result[len(result)-1]
+		if c.High > last.High {
+			last.High = c.High
+		}
+		if c.Low < last.Low {
+			last.Low = c.Low
+		}
+		last.Close = c.Close
+		last.Volume += c.Volume
+	}
+	return result
+}
@@ -0,0 +1,169 @@
+package candles
+
+import (
+	"encoding/json"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+const minute = int64(60000)
+
+func TestAggregate(t *testing.T) {
+	candles := FromChartData(&models.GetTradingviewChartDataResponse{
+		Ticks:  []int64{0, minute, 2 * minute, 3 * minute},
+		Open:   []float64{10, 11, 12, 13},
+		High:   []float64{12, 15, 13, 14},
+		Low:    []float64{9, 10, 8, 12},
+		Close:  []float64{11, 12, 13, 14},
+		Volume: []float64{1, 2, 3, 4},
+	})
+	assert.Len(t, candles, 4)
+
+	assert.Equal(t, []Candle{
+		{Timestamp: 0, Open: 10, High: 15, Low: 8, Close: 13, Volume: 6},
+		{Timestamp: 3 * minute, Open: 13, High: 14, Low: 12, Close: 14, Volume: 4},
+	}, Aggregate(candles, 3*time.Minute))
+}
+
+func TestFetch(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	var mu sync.Mutex
+	var requests []models.GetTradingviewChartDataParams
+	server.Handle("public/get_tradingview_chart_data", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		var p models.GetTradingviewChartDataParams
+		json.Unmarshal(params, &p)
+		mu.Lock()
+		requests = append(requests, p)
+		mu.Unlock()
+		result := models.GetTradingviewChartDataResponse{Status: "ok"}
+		// one 10 minute candle priced by its index, including the end bound
+		ms := 10 * minute
+		for t := p.StartTimestamp - p.StartTimestamp%ms; t <= p.EndTimestamp; t += ms {
+			price := float64(t / ms)
+			result.Ticks = append(result.Ticks, t)
+			result.Open = append(result.Open, price)
+			result.High = append(result.High, price)
+			result.Low = append(result.Low, price)
+			result.Close = append(result.Close, price)
+			result.Volume = append(result.Volume, 1)
+		}
+		return result, nil
+	})
+	client := deribit.New(&deribit.Configuration{Addr: server.HTTPURL})
+
+	start := time.Unix(0, 0)
+	end := start.Add(2500 * 10 * time.Minute)
+	candles, err := Fetch(client, "BTC-PERPETUAL", 20*time.Minute, start, end)
+	assert.Nil(t, err)
+	assert.Len(t, requests, 3)
+	assert.Equal(t, "10", requests[0].Resolution)
+	assert.Len(t, candles, 1251)
+	for i, c := range candles {
+		assert.Equal(t, int64(i)*20*minute, c.Timestamp)
+		if i < 1250 {
+			assert.Equal(t, float64(2), c.Volume)
+			assert.Equal(t, float64(2*i+1), c.Close)
+		}
+	}
+
+	_, err = Fetch(client, "BTC-PERPETUAL", 90*time.Second, start, end)
+	assert.NotNil(t, err)
+}
+
+func TestBuilder(t *testing.T) {
+	builder, err := NewBuilder(nil, "BTC-PERPETUAL", time.Minute)
+	assert.Nil(t, err)
+	var mu sync.Mutex
+	var closed []Candle
+	var updates int
+	builder.On(EventClose, func(c *Candle) {
+		mu.Lock()
+		closed = append(closed, *c)
+		mu.Unlock()
+	})
+	builder.On(EventUpdate, func(c *Candle) {
+		mu.Lock()
+		updates++
+		mu.Unlock()
+	})
+
+	trade := func(ts int64, price float64) {
+		// 10 BTC in USD
+		builder.AddTrade(models.Trade{InstrumentName: "BTC-PERPETUAL", Timestamp: ts, Price: price, Amount: 10 * price})
+	}
+	trade(1000, 100)
+	trade(2000, 105)
+	trade(3000, 95)
+	trade(2*minute+1000, 110)
+	// late trade of a closed candle
+	trade(1500, 1)
+	builder.AddTrade(models.Trade{InstrumentName: "ETH-PERPETUAL", Timestamp: 2*minute + 2000, Price: 1})
+
+	assert.Equal(t, 4, updates)
+	assert.Equal(t, []Candle{
+		{Timestamp: 0, Open: 100, High: 105, Low: 95, Close: 95, Volume: 30},
+		{Timestamp: minute, Open: 95, High: 95, Low: 95, Close: 95},
+	}, closed)
+
+	builder.CloseUntil(time.Unix(0, 4*minute*int64(time.Millisecond)))
+	assert.Len(t, closed, 4)
+	assert.Equal(t, Candle{Timestamp: 3 * minute, Open: 110, High: 110, Low: 110, Close: 110}, closed[3])
+
+	// the first trade of a flat candle sets its open
+	trade(4*minute+1000, 120)
+	current, ok := builder.Current()
+	assert.True(t, ok)
+	assert.Equal(t, Candle{Timestamp: 4 * minute, Open: 120, High: 120, Low: 120, Close: 120, Volume: 10}, current)
+	assert.Len(t, builder.Candles(), 4)
+}
+
+func TestBuilder_Backfill(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.Handle("public/get_tradingview_chart_data", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		var p models.GetTradingviewChartDataParams
+		json.Unmarshal(params, &p)
+		result := models.GetTradingviewChartDataResponse{Status: "ok"}
+		for t := p.StartTimestamp; t <= p.EndTimestamp; t += minute {
+			result.Ticks = append(result.Ticks, t)
+			result.Open = append(result.Open, 100)
+			result.High = append(result.High, 100)
+			result.Low = append(result.Low, 100)
+			result.Close = append(result.Close, 100)
+			result.Volume = append(result.Volume, 1)
+		}
+		return result, nil
+	})
+	server.Respond("public/get_last_trades_by_instrument_and_time", models.GetLastTradesResponse{Trades: []models.Trade{
+		{TradeSeq: 10, InstrumentName: "BTC-PERPETUAL", Timestamp: 5*minute + 1000, Price: 200, Amount: 400},
+		{TradeSeq: 11, InstrumentName: "BTC-PERPETUAL", Timestamp: 5*minute + 2000, Price: 100, Amount: 100},
+	}})
+	client := deribit.New(&deribit.Configuration{Addr: server.HTTPURL})
+
+	builder, err := NewBuilder(client, "BTC-PERPETUAL", time.Minute)
+	assert.Nil(t, err)
+	builder.MaxCandles = 3
+	builder.now = func() time.Time {
+		return time.Unix(0, (5*minute+3000)*int64(time.Millisecond))
+	}
+	assert.Nil(t, builder.Backfill(time.Unix(0, 0)))
+	candles := builder.Candles()
+	assert.Len(t, candles, 3, "the oldest candles beyond MaxCandles are dropped")
+	assert.Equal(t, 4*minute, candles[2].Timestamp)
+
+	current, ok := builder.Current()
+	assert.True(t, ok)
+	assert.Equal(t, Candle{Timestamp: 5 * minute, Open: 200, High: 200, Low: 100, Close: 100, Volume: 3}, current)
+
+	// live trades already counted by the backfill
+	builder.AddTrade(models.Trade{TradeSeq: 11, InstrumentName: "BTC-PERPETUAL", Timestamp: 5*minute + 2000, Price: 100, Amount: 100})
+	builder.AddTrade(models.Trade{TradeSeq: 12, InstrumentName: "BTC-PERPETUAL", Timestamp: 5*minute + 4000, Price: 150, Amount: 150})
+	current, _ = builder.Current()
+	assert.Equal(t, Candle{Timestamp: 5 * minute, Open: 200, High: 200, Low: 100, Close: 150, Volume: 4}, current)
+}
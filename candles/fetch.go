@@ -0,0 +1,58 @@
+package candles
+
+import (
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/models"
+	"time"
+)
+
+// MaxCandlesPerRequest is the number of candles requested per chart data call
+const MaxCandlesPerRequest = 1000
+
+// Fetch returns the candles of period of instrument opening from start to end,
+// chunking the chart data calls. Periods which are not a resolution of the
+// chart data are aggregated from the longest resolution they are a multiple of.
+func Fetch(client *deribit.Client, instrument string, period time.Duration, start time.Time, end time.Time) ([]Candle, error) {
+	resolution, base, err := baseResolution(period)
+	if err != nil {
+		return nil, err
+	}
+	from := openTime(millis(start), period)
+	to := millis(end)
+	chunk := int64(base/time.Millisecond) * MaxCandlesPerRequest
+
+	var candles []Candle
+	for t := from; t <= to; t += chunk {
+		chunkEnd := t + chunk - 1
+		if chunkEnd > to {
+			chunkEnd = to
+		}
+		data, err := client.GetTradingviewChartData(&models.GetTradingviewChartDataParams{
+			InstrumentName: instrument,
+			StartTimestamp: t,
+			EndTimestamp:   chunkEnd,
+			Resolution:     resolution,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range FromChartData(&data) {
+			// chunks may overlap at their bounds
+			if c.Timestamp < t || len(candles) > 0 && c.Timestamp <= candles[len(candles)-1].Timestamp {
+				continue
+			}
+			candles = append(candles, c)
+		}
+	}
+	return Aggregate(candles, period), nil
+}
+
+// millis returns t in milliseconds since epoch
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// msTime returns the time of ms milliseconds since epoch
+func msTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
@@ -2,6 +2,7 @@ package deribit
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"github.com/sourcegraph/jsonrpc2"
 	"log"
 	"net/http"
+	"net/url"
 	"nhooyr.io/websocket"
 	"strings"
 	"sync"
@@ -19,14 +21,21 @@ import (
 const (
 	RealBaseURL = "wss://www.deribit.com/ws/api/v2/"
 	TestBaseURL = "wss://test.deribit.com/ws/api/v2/"
+
+	RealBaseHTTPURL = "https://www.deribit.com/api/v2/"
+	TestBaseHTTPURL = "https://test.deribit.com/api/v2/"
 )
 
 const (
 	MaxTryTimes = 10000
+
+	DefaultDialTimeout = 10 * time.Second
+	DefaultReadLimit   = 32768 * 64
 )
 
 var (
 	ErrAuthenticationIsRequired = errors.New("authentication is required")
+	ErrNotConnected             = errors.New("not connected")
 )
 
 // Event is wrapper of received event
@@ -42,6 +51,29 @@ type Configuration struct {
 	SecretKey     string `json:"secret_key"`
 	AutoReconnect bool   `json:"auto_reconnect"`
 	DebugMode     bool   `json:"debug_mode"`
+
+	// Transport overrides the transport chosen from Addr.
+	// By default ws(s) addresses use the WebSocket API and http(s) addresses the HTTP API.
+	Transport Transport `json:"-"`
+
+	// DialTimeout is the timeout of the WebSocket handshake, defaults to DefaultDialTimeout
+	DialTimeout time.Duration `json:"dial_timeout"`
+	// ReadLimit is the maximum size in bytes of a WebSocket message, defaults to DefaultReadLimit
+	ReadLimit int64 `json:"read_limit"`
+	// CompressionMode controls the WebSocket compression mode
+	CompressionMode websocket.CompressionMode `json:"compression_mode"`
+	// Header is added to the WebSocket handshake request
+	Header http.Header `json:"-"`
+	// HTTPClient is used for the WebSocket handshake and the HTTP transport.
+	// Its Timeout must be zero, DialTimeout bounds the handshake instead.
+	// When nil a client is built from Proxy and TLSConfig.
+	HTTPClient *http.Client `json:"-"`
+	// Proxy returns the proxy for a request, defaults to http.ProxyFromEnvironment
+	Proxy func(*http.Request) (*url.URL, error) `json:"-"`
+	// TLSConfig is used for wss and https connections, e.g. for a custom CA bundle
+	TLSConfig *tls.Config `json:"-"`
+	// Recorder records every frame of the WebSocket sessions, see NewReplayer to play them back
+	Recorder *Recorder `json:"-"`
 }
 
 type Client struct {
@@ -54,6 +86,16 @@ type Client struct {
 
 	conn        *websocket.Conn
 	rpcConn     *jsonrpc2.Conn
+	transport   Transport
+	streaming   bool
+	dialTimeout time.Duration
+	dialOptions *websocket.DialOptions
+	readLimit   int64
+	recorder    *Recorder
+	rounding    struct {
+		registry *InstrumentRegistry
+		rounding Rounding
+	}
 	mu          sync.RWMutex
 	heartCancel chan struct{}
 	isConnected bool
@@ -70,6 +112,11 @@ type Client struct {
 }
 
 func New(cfg *Configuration) *Client {
+	return newClientWithEmitter(cfg, emission.NewEmitter())
+}
+
+// newClientWithEmitter creates a Client emitting events on emitter
+func newClientWithEmitter(cfg *Configuration, emitter *emission.Emitter) *Client {
 	ctx := cfg.Ctx
 	if ctx == nil {
 		ctx = context.Background()
@@ -82,7 +129,30 @@ func New(cfg *Configuration) *Client {
 		autoReconnect:    cfg.AutoReconnect,
 		debugMode:        cfg.DebugMode,
 		subscriptionsMap: make(map[string]struct{}),
-		emitter:          emission.NewEmitter(),
+		emitter:          emitter,
+		dialTimeout:      cfg.DialTimeout,
+		readLimit:        cfg.ReadLimit,
+		recorder:         cfg.Recorder,
+	}
+	if client.dialTimeout <= 0 {
+		client.dialTimeout = DefaultDialTimeout
+	}
+	if client.readLimit <= 0 {
+		client.readLimit = DefaultReadLimit
+	}
+	httpClient := newHTTPClient(cfg)
+	switch {
+	case cfg.Transport != nil:
+		client.transport = cfg.Transport
+	case isHTTPAddr(cfg.Addr):
+		client.transport = NewHTTPTransport(cfg.Addr, cfg.ApiKey, cfg.SecretKey, httpClient)
+	default:
+		client.streaming = true
+		client.dialOptions = &websocket.DialOptions{
+			HTTPClient:      httpClient,
+			HTTPHeader:      cfg.Header,
+			CompressionMode: cfg.CompressionMode,
+		}
 	}
 	err := client.start()
 	if err != nil {
@@ -113,6 +183,11 @@ func (c *Client) Subscribe(channels []string) {
 }
 
 func (c *Client) subscribe(channels []string) {
+	if !c.streaming {
+		log.Printf("subscriptions require the WebSocket transport")
+		return
+	}
+
 	var publicChannels []string
 	var privateChannels []string
 
@@ -145,6 +220,11 @@ func (c *Client) subscribe(channels []string) {
 }
 
 func (c *Client) start() error {
+	if !c.streaming {
+		c.setIsConnected(true)
+		return nil
+	}
+
 	c.setIsConnected(false)
 	c.subscriptionsMap = make(map[string]struct{})
 	c.conn = nil
@@ -167,7 +247,12 @@ func (c *Client) start() error {
 		return errors.New("connect fail")
 	}
 
-	c.rpcConn = jsonrpc2.NewConn(context.Background(), NewObjectStream(c.conn), c)
+	var stream jsonrpc2.ObjectStream = NewObjectStream(c.conn)
+	if c.recorder != nil {
+		stream = c.recorder.Wrap(stream)
+	}
+	c.rpcConn = jsonrpc2.NewConn(context.Background(), stream, c)
+	c.transport = &rpcTransport{conn: c.rpcConn}
 
 	c.setIsConnected(true)
 
@@ -187,13 +272,19 @@ func (c *Client) start() error {
 		go c.reconnect()
 	}
 
-	go c.heartbeat()
+	go c.heartbeat(c.heartCancel)
 
 	return nil
 }
 
 // Call issues JSONRPC v2 calls
 func (c *Client) Call(method string, params interface{}, result interface{}) (err error) {
+	return c.CallContext(c.ctx, method, params, result)
+}
+
+// CallContext is Call bounded by ctx instead of the client context.
+// A call canceled by ctx may still be executed by the exchange.
+func (c *Client) CallContext(ctx context.Context, method string, params interface{}, result interface{}) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = errors.New(fmt.Sprintf("%v", r))
@@ -201,11 +292,21 @@ func (c *Client) Call(method string, params interface{}, result interface{}) (er
 	}()
 
 	if !c.IsConnected() {
-		return errors.New("not connected")
+		return ErrNotConnected
 	}
 	if params == nil {
 		params = emptyParams
 	}
+	rounded, err := c.roundOrder(params)
+	if err != nil {
+		return fmt.Errorf("%v: %v", method, err)
+	}
+	params = rounded
+	if v, ok := params.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%v: %v", method, err)
+		}
+	}
 
 	if token, ok := params.(privateParams); ok {
 		if c.auth.token == "" {
@@ -214,7 +315,7 @@ func (c *Client) Call(method string, params interface{}, result interface{}) (er
 		token.setToken(c.auth.token)
 	}
 
-	return c.rpcConn.Call(c.ctx, method, params, result)
+	return c.transport.Call(ctx, method, params, result)
 }
 
 // Handle implements jsonrpc2.Handler
@@ -233,13 +334,14 @@ func (c *Client) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.
 	}
 }
 
-func (c *Client) heartbeat() {
+func (c *Client) heartbeat(cancel chan struct{}) {
 	t := time.NewTicker(3 * time.Second)
+	defer t.Stop()
 	for {
 		select {
 		case <-t.C:
 			c.Test()
-		case <-c.heartCancel:
+		case <-cancel:
 			return
 		}
 	}
@@ -260,11 +362,30 @@ func (c *Client) reconnect() {
 }
 
 func (c *Client) connect() (*websocket.Conn, *http.Response, error) {
-	ctx, _ := context.WithTimeout(context.Background(), 10*time.Second)
-	//defer cancel()
-	conn, resp, err := websocket.Dial(ctx, c.addr, &websocket.DialOptions{})
+	ctx, cancel := context.WithTimeout(context.Background(), c.dialTimeout)
+	defer cancel()
+	conn, resp, err := websocket.Dial(ctx, c.addr, c.dialOptions)
 	if err == nil {
-		conn.SetReadLimit(32768 * 64)
+		conn.SetReadLimit(c.readLimit)
 	}
 	return conn, resp, err
 }
+
+// newHTTPClient returns the http.Client configured by cfg,
+// nil means http.DefaultClient
+func newHTTPClient(cfg *Configuration) *http.Client {
+	if cfg.HTTPClient != nil {
+		return cfg.HTTPClient
+	}
+	if cfg.Proxy == nil && cfg.TLSConfig == nil {
+		return nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Proxy != nil {
+		transport.Proxy = cfg.Proxy
+	}
+	if cfg.TLSConfig != nil {
+		transport.TLSClientConfig = cfg.TLSConfig
+	}
+	return &http.Client{Transport: transport}
+}
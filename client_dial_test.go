@@ -0,0 +1,172 @@
+package deribit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"nhooyr.io/websocket"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// wsTestServer is a minimal WebSocket JSONRPC server answering the calls
+// made while a Client connects
+type wsTestServer struct {
+	*httptest.Server
+	URL string
+
+	mu      sync.Mutex
+	headers []http.Header
+	conns   []*wsTestConn
+}
+
+type wsTestConn struct {
+	*jsonrpc2.Conn
+
+	mu       sync.Mutex
+	channels []string
+}
+
+func newWSTestServer(tls bool) *wsTestServer {
+	s := &wsTestServer{}
+	handler := http.HandlerFunc(s.serveHTTP)
+	if tls {
+		s.Server = httptest.NewTLSServer(handler)
+		s.URL = "wss" + strings.TrimPrefix(s.Server.URL, "https")
+	} else {
+		s.Server = httptest.NewServer(handler)
+		s.URL = "ws" + strings.TrimPrefix(s.Server.URL, "http")
+	}
+	return s
+}
+
+func (s *wsTestServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	ws, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	ws.SetReadLimit(DefaultReadLimit)
+
+	// listed before it answers any call, so connections keep the order clients dialed them
+	s.mu.Lock()
+	conn := &wsTestConn{}
+	conn.Conn = jsonrpc2.NewConn(context.Background(), NewObjectStream(ws), jsonrpc2.HandlerWithError(conn.handle))
+	s.headers = append(s.headers, r.Header)
+	s.conns = append(s.conns, conn)
+	s.mu.Unlock()
+
+	<-conn.DisconnectNotify()
+}
+
+func (c *wsTestConn) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+	switch req.Method {
+	case "public/subscribe", "private/subscribe":
+		var params struct {
+			Channels []string `json:"channels"`
+		}
+		if err := json.Unmarshal(*req.Params, &params); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.channels = append(c.channels, params.Channels...)
+		c.mu.Unlock()
+		return params.Channels, nil
+	case "public/set_heartbeat":
+		return "ok", nil
+	}
+	return nil, nil
+}
+
+// Channels returns the channels subscribed on the connection
+func (c *wsTestConn) Channels() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.channels...)
+}
+
+// Headers returns the handshake headers of the accepted connections
+func (s *wsTestServer) Headers() []http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]http.Header(nil), s.headers...)
+}
+
+// Conns returns the accepted connections
+func (s *wsTestServer) Conns() []*wsTestConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*wsTestConn(nil), s.conns...)
+}
+
+func TestDial_Defaults(t *testing.T) {
+	server := newWSTestServer(false)
+	defer server.Close()
+
+	client := New(&Configuration{Addr: server.URL})
+	assert.True(t, client.IsConnected())
+	assert.Equal(t, DefaultDialTimeout, client.dialTimeout)
+	assert.Equal(t, int64(DefaultReadLimit), client.readLimit)
+}
+
+func TestDial_Header(t *testing.T) {
+	server := newWSTestServer(false)
+	defer server.Close()
+
+	header := http.Header{}
+	header.Set("X-Test", "1")
+	New(&Configuration{
+		Addr:        server.URL,
+		Header:      header,
+		DialTimeout: time.Second,
+	})
+	headers := server.Headers()
+	assert.Len(t, headers, 1)
+	assert.Equal(t, "1", headers[0].Get("X-Test"))
+}
+
+func TestDial_ReadLimit(t *testing.T) {
+	server := newWSTestServer(false)
+	defer server.Close()
+
+	client := New(&Configuration{Addr: server.URL, ReadLimit: 1024})
+	conns := server.Conns()
+	assert.Len(t, conns, 1)
+	err := conns[0].Notify(context.Background(), "subscription", map[string]string{
+		"channel": "announcements",
+		"data":    strings.Repeat("x", 2048),
+	})
+	assert.Nil(t, err)
+	select {
+	case <-client.rpcConn.DisconnectNotify():
+	case <-time.After(time.Second):
+		t.Fatal("message above the read limit was accepted")
+	}
+}
+
+func TestDial_TLSConfig(t *testing.T) {
+	server := newWSTestServer(true)
+	defer server.Close()
+
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+	client := New(&Configuration{Addr: server.URL, TLSConfig: tlsConfig})
+	assert.True(t, client.IsConnected())
+	assert.Len(t, server.Headers(), 1)
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	assert.Nil(t, newHTTPClient(&Configuration{}))
+
+	httpClient := &http.Client{}
+	assert.Equal(t, httpClient, newHTTPClient(&Configuration{HTTPClient: httpClient}))
+
+	client := newHTTPClient(&Configuration{Proxy: http.ProxyURL(nil)})
+	assert.NotNil(t, client.Transport.(*http.Transport).Proxy)
+}
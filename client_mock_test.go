@@ -0,0 +1,288 @@
+package deribit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newMockClient(server *deribittest.Server) *Client {
+	cfg := &Configuration{
+		Addr:      server.URL,
+		ApiKey:    "key",
+		SecretKey: "secret",
+	}
+	return New(cfg)
+}
+
+func TestMock_Auth(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SetCredentials("key", "secret")
+
+	client := newMockClient(server)
+	assert.NotEmpty(t, client.auth.token)
+
+	err := client.Auth("key", "wrong")
+	assert.NotNil(t, err)
+}
+
+func TestMock_Subscribe(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	client := newMockClient(server)
+	books := make(chan *models.OrderBookRawNotification, 1)
+	client.On("book.BTC-PERPETUAL.raw", func(e *models.OrderBookRawNotification) {
+		books <- e
+	})
+	orders := make(chan *models.UserOrderNotification, 1)
+	client.On("user.orders.BTC-PERPETUAL.raw", func(e *models.UserOrderNotification) {
+		orders <- e
+	})
+	client.Subscribe([]string{"book.BTC-PERPETUAL.raw", "user.orders.BTC-PERPETUAL.raw"})
+
+	server.NotifyRaw("book.BTC-PERPETUAL.raw", `{"timestamp":1587560603684,"instrument_name":"BTC-PERPETUAL","prev_change_id":1,"change_id":2,"bids":[["new",6961.0,105420.0]],"asks":[]}`)
+	select {
+	case e := <-books:
+		assert.Equal(t, int64(2), e.ChangeID)
+		assert.Equal(t, 6961.0, e.Bids[0].Price)
+	case <-time.After(time.Second):
+		t.Fatal("no book notification")
+	}
+
+	server.NotifyRaw("user.orders.BTC-PERPETUAL.raw", `{"order_id":"1","order_state":"open","instrument_name":"BTC-PERPETUAL","price":6000.0}`)
+	select {
+	case e := <-orders:
+		assert.Equal(t, "1", (*e)[0].OrderID)
+	case <-time.After(time.Second):
+		t.Fatal("no order notification")
+	}
+}
+
+func TestMock_Trading(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	client := newMockClient(server)
+	buy, err := client.Buy(&models.BuyParams{
+		InstrumentName: "BTC-PERPETUAL",
+		Amount:         40,
+		Price:          6000.0,
+		Type:           "limit",
+		Label:          "test",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, models.OrderStateOpen, buy.Order.OrderState)
+	assert.Equal(t, "test", buy.Order.Label)
+
+	open, err := client.GetOpenOrdersByInstrument(&models.GetOpenOrdersByInstrumentParams{InstrumentName: "BTC-PERPETUAL"})
+	assert.Nil(t, err)
+	assert.Len(t, open, 1)
+
+	order, err := client.Cancel(&models.CancelParams{OrderID: buy.Order.OrderID})
+	assert.Nil(t, err)
+	assert.Equal(t, models.OrderStateCancelled, order.OrderState)
+
+	_, err = client.Cancel(&models.CancelParams{OrderID: buy.Order.OrderID})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, server.CallCount("private/cancel"))
+}
+
+func TestMock_Tickers(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SetOrderBook(models.GetOrderBookResponse{
+		InstrumentName: "BTC-PERPETUAL",
+		Bids:           [][]float64{{6000, 10}},
+		Asks:           [][]float64{{6000.5, 20}},
+	})
+	server.RespondError("public/ticker", 10000, "boom")
+
+	client := newMockClient(server)
+	result, errs := client.GetOrderBooks([]string{"BTC-PERPETUAL", "ETH-PERPETUAL"}, 1, 2)
+	assert.Nil(t, errs[0])
+	assert.Nil(t, errs[1])
+	assert.Equal(t, 6000.5, result[0].BestAskPrice)
+	assert.Equal(t, "ETH-PERPETUAL", result[1].InstrumentName)
+
+	_, errs = client.Tickers([]string{"BTC-PERPETUAL"}, 0)
+	assert.NotNil(t, errs[0])
+}
+
+func TestMock_Decimals(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.Handle("private/get_account_summary", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		return json.RawMessage(`{"currency":"BTC","balance":302.60065765000,"equity":1e-8}`), nil
+	})
+
+	client := newMockClient(server)
+	var summary models.PortfolioDecimals
+	err := client.Call("private/get_account_summary", &models.GetAccountSummaryParams{Currency: "BTC"}, &summary)
+	assert.Nil(t, err)
+	assert.Equal(t, "302.60065765000", summary.Balance.String())
+	assert.Equal(t, "0.00000001", summary.Equity.Add(models.Decimal{}).String())
+}
+
+func TestMock_CallBatch(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SetAsync(true)
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server.Handle("public/ticker", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		var p models.TickerParams
+		json.Unmarshal(params, &p)
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		var i int
+		fmt.Sscanf(p.InstrumentName, "BTC-%d", &i)
+		// later calls answer first
+		time.Sleep(time.Duration(10-i) * 5 * time.Millisecond)
+		if i%4 == 3 {
+			return nil, deribittest.Error(deribittest.ErrCodeNotFound, "instrument_not_found")
+		}
+		return models.TickerResponse{InstrumentName: p.InstrumentName}, nil
+	})
+
+	client := newMockClient(server)
+	var names []string
+	for i := 0; i < 10; i++ {
+		names = append(names, fmt.Sprintf("BTC-%d", i))
+	}
+	result, errs := client.Tickers(names, 3)
+	assert.Len(t, result, len(names))
+	assert.Len(t, errs, len(names))
+	for i, name := range names {
+		if i%4 == 3 {
+			assert.NotNil(t, errs[i], name)
+			assert.Empty(t, result[i].InstrumentName, name)
+			continue
+		}
+		assert.Nil(t, errs[i], name)
+		assert.Equal(t, name, result[i].InstrumentName, "results are in the order of the names")
+	}
+	mu.Lock()
+	assert.Equal(t, 3, maxInFlight, "at most maxInFlight calls are outstanding")
+	mu.Unlock()
+}
+
+func TestMock_GetOrderStates(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SetAsync(true)
+
+	client := newMockClient(server)
+	var ids []string
+	for _, price := range []float64{5000, 5100} {
+		buy, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: price})
+		assert.Nil(t, err)
+		ids = append(ids, buy.Order.OrderID)
+	}
+	ids = []string{ids[1], "missing", ids[0]}
+	result, errs := client.GetOrderStates(ids, 0)
+	assert.Nil(t, errs[0])
+	assert.NotNil(t, errs[1])
+	assert.Nil(t, errs[2])
+	assert.Equal(t, 5100.0, float64(result[0].Price))
+	assert.Equal(t, 5000.0, float64(result[2].Price))
+}
+
+func TestMock_HTTP(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SetCredentials("key", "secret")
+
+	client := New(&Configuration{
+		Addr:      server.HTTPURL,
+		ApiKey:    "key",
+		SecretKey: "secret",
+	})
+	buy, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000})
+	assert.Nil(t, err)
+	assert.Equal(t, models.OrderStateOpen, buy.Order.OrderState)
+}
+
+func TestMock_ValidateParams(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	client := newMockClient(server)
+	_, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000, Type: "limt"})
+	assert.EqualError(t, err, `private/buy: invalid type "limt", want one of limit, market, stop_limit, stop_market, take_limit, take_market, market_limit, trailing_stop`)
+	_, err = client.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: models.OrderTypeStopMarket})
+	assert.EqualError(t, err, "private/sell: stop_price is required by stop_market orders")
+	_, err = client.Edit(&models.EditParams{OrderID: "1", Amount: 10, Price: 6000, Advanced: "usdd"})
+	assert.EqualError(t, err, `private/edit: invalid advanced "usdd", want one of usd, implv`)
+	_, ok := server.Order("1")
+	assert.False(t, ok, "invalid orders are not sent")
+
+	_, err = client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000, TimeInForce: models.TimeInForceGoodTilCancelled})
+	assert.Nil(t, err)
+}
+
+func TestMock_Reconnect(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	client := New(&Configuration{
+		Addr:          server.URL,
+		ApiKey:        "key",
+		SecretKey:     "secret",
+		AutoReconnect: true,
+	})
+	client.Subscribe([]string{"trades.BTC-PERPETUAL.raw"})
+	server.DisconnectAll()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(server.Headers()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Len(t, server.Headers(), 2)
+	assert.True(t, server.WaitSubscribed("trades.BTC-PERPETUAL.raw", 5*time.Second))
+}
+
+func TestRedundantFeed_Subscribe(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	feed := NewRedundantFeed(&Configuration{
+		Addr:      server.URL,
+		ApiKey:    "key",
+		SecretKey: "secret",
+	}, 2)
+	books := make(chan *models.OrderBookRawNotification, 4)
+	feed.On("book.BTC-PERPETUAL.raw", func(e *models.OrderBookRawNotification) {
+		books <- e
+	})
+	feed.Subscribe([]string{"book.BTC-PERPETUAL.raw"})
+	assert.Len(t, server.Conns(), 2)
+
+	server.NotifyRaw("book.BTC-PERPETUAL.raw", `{"instrument_name":"BTC-PERPETUAL","change_id":5,"bids":[],"asks":[]}`)
+	select {
+	case e := <-books:
+		assert.Equal(t, int64(5), e.ChangeID)
+	case <-time.After(time.Second):
+		t.Fatal("no book notification")
+	}
+	select {
+	case <-books:
+		t.Fatal("duplicate book notification")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/models"
+	"log"
+	"strings"
+	"time"
+)
+
+// maxBookDepth is the depth of the snapshots fetched after a book gap
+const maxBookDepth = 10000
+
+// backfillCount is the number of trades fetched per backfill request
+const backfillCount = 1000
+
+type event struct {
+	channel  string
+	received time.Time
+	data     interface{}
+}
+
+// capturer writes the notifications of channels to outputs.
+// Every notification is handled by a single goroutine which also recovers
+// sequence gaps: missed trades are fetched again and book gaps are followed
+// by a snapshot.
+type capturer struct {
+	client  *deribit.Client
+	outputs map[*table]*output
+	events  chan event
+	now     func() time.Time
+
+	tradeSeqs map[string]int   // last trade_seq by channel and instrument
+	changeIDs map[string]int64 // last change_id by channel
+}
+
+func newCapturer(client *deribit.Client, dir string, formats []string) *capturer {
+	c := &capturer{
+		client:    client,
+		outputs:   make(map[*table]*output),
+		events:    make(chan event, 4096),
+		now:       time.Now,
+		tradeSeqs: make(map[string]int),
+		changeIDs: make(map[string]int64),
+	}
+	for _, t := range tables {
+		c.outputs[t] = newOutput(dir, t, formats)
+	}
+	return c
+}
+
+// checkChannel returns an error if channel can't be captured
+func checkChannel(channel string) error {
+	for _, prefix := range []string{"trades.", "book.", "ticker.", "deribit_price_index."} {
+		if strings.HasPrefix(channel, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported channel %q", channel)
+}
+
+// subscribe subscribes to channels, the client resubscribes after reconnects
+func (c *capturer) subscribe(channels []string) {
+	for _, channel := range channels {
+		channel := channel
+		c.client.On(channel, func(data interface{}) {
+			c.events <- event{channel: channel, received: c.now(), data: data}
+		})
+	}
+	c.client.Subscribe(channels)
+}
+
+// run writes the notifications until ctx is done, then closes the files
+func (c *capturer) run(ctx context.Context) error {
+	flush := time.NewTicker(time.Second)
+	defer flush.Stop()
+
+	for {
+		select {
+		case e := <-c.events:
+			if err := c.process(e); err != nil {
+				c.close()
+				return err
+			}
+		case <-flush.C:
+			for _, o := range c.outputs {
+				if err := o.Flush(); err != nil {
+					c.close()
+					return err
+				}
+			}
+		case <-ctx.Done():
+			return c.close()
+		}
+	}
+}
+
+func (c *capturer) close() error {
+	var err error
+	for _, o := range c.outputs {
+		if e := o.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (c *capturer) write(t *table, received time.Time, row []interface{}) error {
+	return c.outputs[t].Write(received, row)
+}
+
+func (c *capturer) process(e event) error {
+	switch data := e.data.(type) {
+	case *models.TradesNotification:
+		return c.processTrades(e, *data)
+	case *models.OrderBookRawNotification:
+		return c.processBook(e, data.Timestamp, data.InstrumentName, data.ChangeID, data.PrevChangeID, data.Bids, data.Asks)
+	case *models.OrderBookNotification:
+		return c.processBook(e, data.Timestamp, data.InstrumentName, data.ChangeID, data.PrevChangeID, data.Bids, data.Asks)
+	case *models.OrderBookGroupNotification:
+		return c.writeSnapshot(e.received, data.Timestamp, data.InstrumentName, data.ChangeID, data.Bids, data.Asks)
+	case *models.TickerNotification:
+		return c.write(tickerTable, e.received, tickerRow(data))
+	case *models.DeribitPriceIndexNotification:
+		return c.write(indexTable, e.received, indexRow(data))
+	}
+	return nil
+}
+
+func (c *capturer) processTrades(e event, trades []models.Trade) error {
+	for i := range trades {
+		trade := &trades[i]
+		key := e.channel + " " + trade.InstrumentName
+		last, ok := c.tradeSeqs[key]
+		if ok && trade.TradeSeq <= last {
+			// already written, e.g. by a backfill
+			continue
+		}
+		if ok && trade.TradeSeq > last+1 {
+			log.Printf("%v: trade_seq gap %v-%v", key, last+1, trade.TradeSeq-1)
+			if err := c.backfill(e.received, trade.InstrumentName, last+1, trade.TradeSeq-1); err != nil {
+				return err
+			}
+		}
+		if err := c.write(tradesTable, e.received, tradeRow(trade, false)); err != nil {
+			return err
+		}
+		c.tradeSeqs[key] = trade.TradeSeq
+	}
+	return nil
+}
+
+// backfill writes the trades of instrument with trade_seq from start to end
+func (c *capturer) backfill(received time.Time, instrument string, start int, end int) error {
+	next := start
+	for next <= end {
+		result, err := c.client.GetLastTradesByInstrument(&models.GetLastTradesByInstrumentParams{
+			InstrumentName: instrument,
+			StartSeq:       next,
+			EndSeq:         end,
+			Count:          backfillCount,
+			IncludeOld:     true,
+			Sorting:        "asc",
+		})
+		if err != nil {
+			log.Printf("%v: backfill of trades %v-%v failed: %v", instrument, next, end, err)
+			return nil
+		}
+		progress := false
+		for i := range result.Trades {
+			trade := &result.Trades[i]
+			if trade.TradeSeq < next || trade.TradeSeq > end {
+				continue
+			}
+			if err := c.write(tradesTable, received, tradeRow(trade, true)); err != nil {
+				return err
+			}
+			next = trade.TradeSeq + 1
+			progress = true
+		}
+		if !progress || !result.HasMore {
+			break
+		}
+	}
+	if next <= end {
+		log.Printf("%v: trades %v-%v could not be backfilled", instrument, next, end)
+	}
+	return nil
+}
+
+func (c *capturer) processBook(e event, timestamp int64, instrument string, changeID int64, prevChangeID int64, bids []models.OrderBookNotificationItem, asks []models.OrderBookNotificationItem) error {
+	last, ok := c.changeIDs[e.channel]
+	gap := ok && prevChangeID != 0 && prevChangeID != last
+	c.changeIDs[e.channel] = changeID
+
+	for _, side := range []struct {
+		name  string
+		items []models.OrderBookNotificationItem
+	}{{"bid", bids}, {"ask", asks}} {
+		for _, item := range side.items {
+			row := bookRow(timestamp, instrument, changeID, prevChangeID, side.name, item.Action, item.Price, item.Amount)
+			if err := c.write(bookTable, e.received, row); err != nil {
+				return err
+			}
+		}
+	}
+	if !gap {
+		return nil
+	}
+
+	log.Printf("%v: change_id gap %v-%v", e.channel, last, prevChangeID)
+	book, err := c.client.GetOrderBook(&models.GetOrderBookParams{InstrumentName: instrument, Depth: maxBookDepth})
+	if err != nil {
+		log.Printf("%v: snapshot failed: %v", instrument, err)
+		return nil
+	}
+	return c.writeSnapshot(e.received, book.Timestamp, instrument, int64(book.ChangeID), book.Bids, book.Asks)
+}
+
+// writeSnapshot writes a full book with action snapshot
+func (c *capturer) writeSnapshot(received time.Time, timestamp int64, instrument string, changeID int64, bids [][]float64, asks [][]float64) error {
+	for _, side := range []struct {
+		name   string
+		levels [][]float64
+	}{{"bid", bids}, {"ask", asks}} {
+		for _, level := range side.levels {
+			if len(level) < 2 {
+				continue
+			}
+			row := bookRow(timestamp, instrument, changeID, 0, side.name, "snapshot", level[0], level[1])
+			if err := c.write(bookTable, received, row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCapturer_Gaps(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.Handle("public/get_last_trades_by_instrument", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		return models.GetLastTradesResponse{Trades: []models.Trade{
+			{TradeSeq: 2, InstrumentName: "BTC-PERPETUAL", Price: 6001, Amount: 20},
+		}}, nil
+	})
+	server.SeedBook("BTC-PERPETUAL", [][]float64{{5999, 10}}, [][]float64{{6001, 10}})
+
+	dir, err := ioutil.TempDir("", "capture")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	client := deribit.New(&deribit.Configuration{Addr: server.URL})
+	c := newCapturer(client, dir, []string{formatCSV, formatParquet})
+	received := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	events := []event{
+		{"trades.BTC-PERPETUAL.raw", received, &models.TradesNotification{{TradeSeq: 1, InstrumentName: "BTC-PERPETUAL", Price: 6000, Amount: 10}}},
+		{"trades.BTC-PERPETUAL.raw", received, &models.TradesNotification{{TradeSeq: 3, InstrumentName: "BTC-PERPETUAL", Price: 6002, Amount: 30}}},
+		{"trades.BTC-PERPETUAL.raw", received, &models.TradesNotification{{TradeSeq: 3, InstrumentName: "BTC-PERPETUAL", Price: 6002, Amount: 30}}},
+		{"book.BTC-PERPETUAL.raw", received, &models.OrderBookRawNotification{InstrumentName: "BTC-PERPETUAL", ChangeID: 1,
+			Bids: []models.OrderBookNotificationItem{{Action: "new", Price: 5999, Amount: 10}}}},
+		{"book.BTC-PERPETUAL.raw", received, &models.OrderBookRawNotification{InstrumentName: "BTC-PERPETUAL", ChangeID: 5, PrevChangeID: 4,
+			Asks: []models.OrderBookNotificationItem{{Action: "change", Price: 6001, Amount: 5}}}},
+		{"deribit_price_index.btc_usd", received.Add(time.Hour), &models.DeribitPriceIndexNotification{IndexName: "btc_usd", Price: 6000}},
+	}
+	for _, e := range events {
+		assert.Nil(t, c.process(e))
+	}
+	assert.Nil(t, c.close())
+
+	trades := readCSV(t, filepath.Join(dir, "trades", "2020-05-01T10.csv"))
+	assert.Len(t, trades, 4)
+	assert.Equal(t, []string{"1", "2", "3"}, []string{trades[1][2], trades[2][2], trades[3][2]})
+	assert.Equal(t, "true", trades[2][10])
+
+	book := readCSV(t, filepath.Join(dir, "book", "2020-05-01T10.csv"))
+	assert.Len(t, book, 5)
+	assert.Equal(t, "snapshot", book[3][5])
+	assert.Equal(t, "snapshot", book[4][5])
+
+	_, err = os.Stat(filepath.Join(dir, "deribit_price_index", "2020-05-01T11.csv"))
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "trades", "2020-05-01T10.parquet"))
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(data, parquetMagic))
+	assert.True(t, bytes.HasSuffix(data, parquetMagic))
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	assert.Nil(t, err)
+	return records
+}
@@ -0,0 +1,77 @@
+// Command deribit-capture captures market data channels to hourly CSV and Parquet files.
+//
+// Usage:
+//
+//	deribit-capture -dir data -format csv,parquet trades.BTC-PERPETUAL.raw book.BTC-PERPETUAL.raw
+//
+// Rows are written to dir/<table>/<yyyy-mm-ddThh>.<format> with the tables
+// trades, book, ticker and deribit_price_index. The connection is reopened
+// and resubscribed after disconnects; missed trades are backfilled (with
+// backfill=true) and book change_id gaps are followed by snapshot rows.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/frankrap/deribit-api"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+func main() {
+	addr := flag.String("addr", deribit.RealBaseURL, "WebSocket API address")
+	dir := flag.String("dir", ".", "output directory")
+	format := flag.String("format", "csv,parquet", "comma separated output formats: csv, parquet")
+	debug := flag.Bool("debug", false, "log every notification")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %v [flags] channel...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var channels []string
+	for _, arg := range flag.Args() {
+		for _, channel := range strings.Split(arg, ",") {
+			if channel == "" {
+				continue
+			}
+			if err := checkChannel(channel); err != nil {
+				log.Fatal(err)
+			}
+			channels = append(channels, channel)
+		}
+	}
+	if len(channels) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	formats := strings.Split(*format, ",")
+	for _, f := range formats {
+		if f != formatCSV && f != formatParquet {
+			log.Fatalf("unknown format %q", f)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	client := deribit.New(&deribit.Configuration{
+		Addr:          *addr,
+		AutoReconnect: true,
+		DebugMode:     *debug,
+	})
+	c := newCapturer(client, *dir, formats)
+	c.subscribe(channels)
+	if err := c.run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
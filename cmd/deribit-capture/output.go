@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Output formats
+const (
+	formatCSV     = "csv"
+	formatParquet = "parquet"
+)
+
+type rowWriter interface {
+	Write(row []interface{}) error
+	Flush() error
+	Close() error
+}
+
+type csvWriter struct {
+	f      *os.File
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(f *os.File, t *table) (*csvWriter, error) {
+	w := &csvWriter{f: f, w: csv.NewWriter(f), record: make([]string, len(t.columns))}
+	for i, column := range t.columns {
+		w.record[i] = column.name
+	}
+	if err := w.w.Write(w.record); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *csvWriter) Write(row []interface{}) error {
+	for i, v := range row {
+		w.record[i] = formatValue(v)
+	}
+	return w.w.Write(w.record)
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) Close() error {
+	if err := w.Flush(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// output writes the rows of a table to files rotated every hour,
+// dir/table/2006-01-02T15.format, one per format
+type output struct {
+	dir     string
+	table   *table
+	formats []string
+
+	hour    time.Time
+	writers []rowWriter
+}
+
+func newOutput(dir string, t *table, formats []string) *output {
+	return &output{dir: dir, table: t, formats: formats}
+}
+
+// Write writes a row received at now
+func (o *output) Write(now time.Time, row []interface{}) error {
+	hour := now.UTC().Truncate(time.Hour)
+	if o.writers == nil || !hour.Equal(o.hour) {
+		if err := o.Close(); err != nil {
+			return err
+		}
+		if err := o.open(hour); err != nil {
+			return err
+		}
+	}
+	for _, w := range o.writers {
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *output) open(hour time.Time) error {
+	dir := filepath.Join(o.dir, o.table.name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, format := range o.formats {
+		f, err := createFile(dir, hour.Format("2006-01-02T15"), format)
+		if err != nil {
+			return err
+		}
+		var w rowWriter
+		switch format {
+		case formatCSV:
+			w, err = newCSVWriter(f, o.table)
+		case formatParquet:
+			w, err = newParquetWriter(f, o.table)
+		default:
+			err = fmt.Errorf("unknown format %q", format)
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+		o.writers = append(o.writers, w)
+	}
+	o.hour = hour
+	return nil
+}
+
+// createFile creates name.ext in dir, or name-N.ext if it exists e.g. after a restart
+func createFile(dir string, name string, ext string) (*os.File, error) {
+	path := filepath.Join(dir, name+"."+ext)
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !os.IsExist(err) {
+			return f, err
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%v-%v.%v", name, i, ext))
+	}
+}
+
+// Flush flushes the CSV files and the due Parquet row groups, Parquet files are only complete once closed
+func (o *output) Flush() error {
+	for _, w := range o.writers {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the current files
+func (o *output) Close() error {
+	var err error
+	for _, w := range o.writers {
+		if e := w.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	o.writers = nil
+	return err
+}
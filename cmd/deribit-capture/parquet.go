@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// Parquet physical types, converted types and codecs, see parquet.thrift
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+
+	parquetRequired = 0
+	parquetPlain    = 0
+	parquetRLE      = 3
+	parquetDataPage = 0
+	parquetGzip     = 2
+)
+
+const (
+	// parquetRowGroupBytes is the size of the buffered values a row group is written at
+	parquetRowGroupBytes = 16 << 20
+	// parquetRowGroupInterval is the age of the oldest buffered row a row group is written at by Flush,
+	// so quiet channels don't keep the rows of the whole hour in memory
+	parquetRowGroupInterval = time.Minute
+)
+
+var parquetMagic = []byte("PAR1")
+
+// parquetWriter writes rows of a table to a Parquet file with required columns,
+// one PLAIN encoded gzip compressed data page per column chunk
+type parquetWriter struct {
+	w         *bufio.Writer
+	closer    io.Closer
+	table     *table
+	offset    int64
+	rows      int
+	first     time.Time // time the first buffered row was written
+	columns   []bytes.Buffer
+	bits      []byte // pending bits of boolean columns
+	rowGroups []parquetRowGroup
+	numRows   int64
+
+	rowGroupBytes    int
+	rowGroupInterval time.Duration
+}
+
+type parquetRowGroup struct {
+	columns []parquetColumnChunk
+	size    int64
+	rows    int64
+}
+
+type parquetColumnChunk struct {
+	offset           int64
+	values           int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+func newParquetWriter(w io.WriteCloser, t *table) (*parquetWriter, error) {
+	p := &parquetWriter{
+		w:       bufio.NewWriter(w),
+		closer:  w,
+		table:   t,
+		columns: make([]bytes.Buffer, len(t.columns)),
+		bits:    make([]byte, len(t.columns)),
+
+		rowGroupBytes:    parquetRowGroupBytes,
+		rowGroupInterval: parquetRowGroupInterval,
+	}
+	if _, err := p.w.Write(parquetMagic); err != nil {
+		return nil, err
+	}
+	p.offset = int64(len(parquetMagic))
+	return p, nil
+}
+
+// Write buffers row, the buffered rows are written as a row group once their
+// values reach rowGroupBytes
+func (p *parquetWriter) Write(row []interface{}) error {
+	if p.rows == 0 {
+		p.first = time.Now()
+	}
+	size := 0
+	for i, column := range p.table.columns {
+		buf := &p.columns[i]
+		switch column.kind {
+		case kindInt, kindTime:
+			binary.Write(buf, binary.LittleEndian, row[i].(int64))
+		case kindFloat:
+			binary.Write(buf, binary.LittleEndian, math.Float64bits(row[i].(float64)))
+		case kindString:
+			s := row[i].(string)
+			binary.Write(buf, binary.LittleEndian, uint32(len(s)))
+			buf.WriteString(s)
+		case kindBool:
+			if row[i].(bool) {
+				p.bits[i] |= 1 << uint(p.rows%8)
+			}
+			if p.rows%8 == 7 {
+				buf.WriteByte(p.bits[i])
+				p.bits[i] = 0
+			}
+		}
+		size += buf.Len()
+	}
+	p.rows++
+	if size >= p.rowGroupBytes {
+		return p.flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered rows as a row group once the oldest is rowGroupInterval old,
+// and flushes the written row groups to the file. The file is only readable once closed.
+func (p *parquetWriter) Flush() error {
+	if p.rows > 0 && time.Since(p.first) >= p.rowGroupInterval {
+		if err := p.flush(); err != nil {
+			return err
+		}
+	}
+	return p.w.Flush()
+}
+
+// flush writes the buffered rows as a row group
+func (p *parquetWriter) flush() error {
+	if p.rows == 0 {
+		return nil
+	}
+	group := parquetRowGroup{rows: int64(p.rows)}
+	for i, column := range p.table.columns {
+		buf := &p.columns[i]
+		if column.kind == kindBool && p.rows%8 != 0 {
+			buf.WriteByte(p.bits[i])
+			p.bits[i] = 0
+		}
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		gz.Write(buf.Bytes())
+		if err := gz.Close(); err != nil {
+			return err
+		}
+
+		var header thriftWriter
+		header.i32(1, parquetDataPage)
+		header.i32(2, int32(buf.Len()))
+		header.i32(3, int32(compressed.Len()))
+		header.beginStruct(5)
+		header.i32(1, int32(p.rows))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.endStruct()
+		header.stop()
+
+		chunk := parquetColumnChunk{
+			offset:           p.offset,
+			values:           int64(p.rows),
+			uncompressedSize: int64(header.buf.Len() + buf.Len()),
+			compressedSize:   int64(header.buf.Len() + compressed.Len()),
+		}
+		if _, err := p.w.Write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if _, err := p.w.Write(compressed.Bytes()); err != nil {
+			return err
+		}
+		p.offset += chunk.compressedSize
+		group.size += chunk.uncompressedSize
+		group.columns = append(group.columns, chunk)
+		buf.Reset()
+	}
+	p.rowGroups = append(p.rowGroups, group)
+	p.numRows += int64(p.rows)
+	p.rows = 0
+	return nil
+}
+
+// Close writes the pending row group and the footer, then closes the file
+func (p *parquetWriter) Close() error {
+	if err := p.flush(); err != nil {
+		p.closer.Close()
+		return err
+	}
+
+	var meta thriftWriter
+	meta.i32(1, 1)
+	meta.beginList(2, thriftStruct, len(p.table.columns)+1)
+	meta.beginListStruct()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(p.table.columns)))
+	meta.endStruct()
+	for _, column := range p.table.columns {
+		meta.beginListStruct()
+		meta.i32(1, column.parquetType())
+		meta.i32(3, parquetRequired)
+		meta.binary(4, column.name)
+		switch column.kind {
+		case kindString:
+			meta.i32(6, parquetUTF8)
+		case kindTime:
+			meta.i32(6, parquetTimestampMillis)
+		}
+		meta.endStruct()
+	}
+	meta.i64(3, p.numRows)
+	meta.beginList(4, thriftStruct, len(p.rowGroups))
+	for _, group := range p.rowGroups {
+		meta.beginListStruct()
+		meta.beginList(1, thriftStruct, len(group.columns))
+		for i, chunk := range group.columns {
+			column := p.table.columns[i]
+			meta.beginListStruct()
+			meta.i64(2, chunk.offset)
+			meta.beginStruct(3)
+			meta.i32(1, column.parquetType())
+			meta.beginList(2, thriftI32, 2)
+			meta.listI32(parquetPlain)
+			meta.listI32(parquetRLE)
+			meta.beginList(3, thriftBinary, 1)
+			meta.listBinary(column.name)
+			meta.i32(4, parquetGzip)
+			meta.i64(5, chunk.values)
+			meta.i64(6, chunk.uncompressedSize)
+			meta.i64(7, chunk.compressedSize)
+			meta.i64(9, chunk.offset)
+			meta.endStruct()
+			meta.endStruct()
+		}
+		meta.i64(2, group.size)
+		meta.i64(3, group.rows)
+		meta.endStruct()
+	}
+	meta.binary(6, "deribit-capture")
+	meta.stop()
+
+	p.w.Write(meta.buf.Bytes())
+	binary.Write(p.w, binary.LittleEndian, uint32(meta.buf.Len()))
+	p.w.Write(parquetMagic)
+	if err := p.w.Flush(); err != nil {
+		p.closer.Close()
+		return err
+	}
+	return p.closer.Close()
+}
+
+func (c column) parquetType() int32 {
+	switch c.kind {
+	case kindInt, kindTime:
+		return parquetInt64
+	case kindFloat:
+		return parquetDouble
+	case kindBool:
+		return parquetBoolean
+	}
+	return parquetByteArray
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol
+type thriftWriter struct {
+	buf    bytes.Buffer
+	last   int16
+	fields []int16 // last field ids of the enclosing structs
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.zigzag(int64(id))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	t.buf.Write(b[:n])
+}
+
+func (t *thriftWriter) zigzag(v int64) {
+	t.varint(uint64(v<<1 ^ v>>63))
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.zigzag(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.listBinary(s)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.beginListStruct()
+}
+
+// beginListStruct begins a struct element of a list
+func (t *thriftWriter) beginListStruct() {
+	t.fields = append(t.fields, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.stop()
+	t.last = t.fields[len(t.fields)-1]
+	t.fields = t.fields[:len(t.fields)-1]
+}
+
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
+
+func (t *thriftWriter) beginList(id int16, elem byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elem)
+	} else {
+		t.buf.WriteByte(0xf0 | elem)
+		t.varint(uint64(size))
+	}
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) listBinary(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// thriftStructValue is a Thrift struct decoded without its schema, by field id
+type thriftStructValue map[int16]interface{}
+
+// thriftReader decodes the Thrift compact protocol independently of thriftWriter,
+// following parquet.thrift and the Thrift compact protocol specification
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) readStruct() thriftStructValue {
+	s := thriftStructValue{}
+	var id int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return s
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag())
+		}
+		s[id] = r.readValue(header & 0x0f)
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) interface{} {
+	switch typ {
+	case 1, 2: // booleans of struct fields are encoded in their type
+		return typ == 1
+	case 3:
+		return int64(int8(r.byte()))
+	case 4, 5, 6:
+		return r.zigzag()
+	case 7:
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos:]))
+		r.pos += 8
+		return v
+	case 8:
+		n := int(r.varint())
+		v := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return v
+	case 9:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		var list []interface{}
+		for i := 0; i < size; i++ {
+			if elem := header & 0x0f; elem == 1 || elem == 2 {
+				list = append(list, r.byte() == 1)
+			} else {
+				list = append(list, r.readValue(elem))
+			}
+		}
+		return list
+	case 12:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unsupported thrift type %v", typ))
+}
+
+// readParquet returns the file metadata and the values of every column of the Parquet file at path
+func readParquet(t *testing.T, path string) (thriftStructValue, [][]interface{}) {
+	data, err := ioutil.ReadFile(path)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "PAR1", string(data[:4]))
+	assert.Equal(t, "PAR1", string(data[len(data)-4:]))
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &thriftReader{data: data[:len(data)-8], pos: len(data) - 8 - footerSize}
+	meta := footer.readStruct()
+
+	schema := meta[2].([]interface{})
+	columns := make([][]interface{}, len(schema)-1)
+	for _, group := range meta[4].([]interface{}) {
+		for i, chunk := range group.(thriftStructValue)[1].([]interface{}) {
+			chunkMeta := chunk.(thriftStructValue)[3].(thriftStructValue)
+			assert.Equal(t, int64(parquetGzip), chunkMeta[4])
+			page := &thriftReader{data: data, pos: int(chunkMeta[9].(int64))}
+			header := page.readStruct()
+			assert.Equal(t, int64(parquetDataPage), header[1])
+			values := int(header[5].(thriftStructValue)[1].(int64))
+			compressed := data[page.pos : page.pos+int(header[3].(int64))]
+			gz, err := gzip.NewReader(bytes.NewReader(compressed))
+			if !assert.Nil(t, err) {
+				t.FailNow()
+			}
+			plain, err := ioutil.ReadAll(gz)
+			assert.Nil(t, err)
+			assert.Equal(t, int(header[2].(int64)), len(plain))
+			columns[i] = append(columns[i], decodePlain(schema[i+1].(thriftStructValue)[1].(int64), plain, values)...)
+		}
+	}
+	return meta, columns
+}
+
+// decodePlain decodes PLAIN encoded values of a physical type
+func decodePlain(typ int64, data []byte, values int) []interface{} {
+	var decoded []interface{}
+	for i := 0; i < values; i++ {
+		switch typ {
+		case parquetBoolean:
+			decoded = append(decoded, data[i/8]&(1<<uint(i%8)) != 0)
+		case parquetInt64:
+			decoded = append(decoded, int64(binary.LittleEndian.Uint64(data)))
+			data = data[8:]
+		case parquetDouble:
+			decoded = append(decoded, math.Float64frombits(binary.LittleEndian.Uint64(data)))
+			data = data[8:]
+		case parquetByteArray:
+			n := binary.LittleEndian.Uint32(data)
+			decoded = append(decoded, string(data[4:4+n]))
+			data = data[4+n:]
+		}
+	}
+	return decoded
+}
+
+func testTradeRow(i int) []interface{} {
+	return []interface{}{1588329000000 + int64(i), "BTC-PERPETUAL", int64(i + 1), fmt.Sprintf("t%d", i),
+		6000 + float64(i%100)/2, float64(i%7 + 1), []string{"buy", "sell"}[i%2], int64(i % 4), 5999.5, 0.0, i%3 == 0}
+}
+
+func TestParquetWriter_RoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parquet")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "trades.parquet")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+
+	w, err := newParquetWriter(f, tradesTable)
+	assert.Nil(t, err)
+	w.rowGroupBytes = 4096
+	// several row groups ending with partial bytes of booleans
+	n := 213
+	for i := 0; i < n; i++ {
+		assert.Nil(t, w.Write(testTradeRow(i)))
+	}
+	assert.Nil(t, w.Close())
+
+	meta, columns := readParquet(t, path)
+	assert.Equal(t, int64(n), meta[3])
+	assert.True(t, len(meta[4].([]interface{})) > 1)
+
+	schema := meta[2].([]interface{})
+	assert.Len(t, schema, len(tradesTable.columns)+1)
+	assert.Equal(t, int64(len(tradesTable.columns)), schema[0].(thriftStructValue)[5])
+	for i, column := range tradesTable.columns {
+		element := schema[i+1].(thriftStructValue)
+		assert.Equal(t, column.name, element[4])
+		assert.Equal(t, int64(column.parquetType()), element[1])
+		assert.Equal(t, int64(parquetRequired), element[3])
+	}
+	assert.Equal(t, int64(parquetTimestampMillis), schema[1].(thriftStructValue)[6])
+	assert.Equal(t, int64(parquetUTF8), schema[2].(thriftStructValue)[6])
+
+	for i := 0; i < n; i++ {
+		row := make([]interface{}, len(columns))
+		for j := range columns {
+			row[j] = columns[j][i]
+		}
+		assert.Equal(t, testTradeRow(i), row)
+	}
+}
+
+func TestParquetWriter_Flush(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parquet")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "trades.parquet")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+
+	w, err := newParquetWriter(f, tradesTable)
+	assert.Nil(t, err)
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, w.Write(testTradeRow(i)))
+	}
+	// rows younger than the interval stay buffered
+	assert.Nil(t, w.Flush())
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), info.Size())
+
+	w.rowGroupInterval = 0
+	assert.Nil(t, w.Flush())
+	info, err = os.Stat(path)
+	assert.Nil(t, err)
+	assert.True(t, info.Size() > 4)
+
+	assert.Nil(t, w.Write(testTradeRow(5)))
+	assert.Nil(t, w.Close())
+	meta, columns := readParquet(t, path)
+	assert.Equal(t, int64(6), meta[3])
+	assert.Len(t, meta[4].([]interface{}), 2)
+	assert.Equal(t, []interface{}{int64(1), int64(2), int64(3), int64(4), int64(5), int64(6)}, columns[2])
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"github.com/frankrap/deribit-api/models"
+	"strconv"
+)
+
+// Kinds of column values
+const (
+	kindInt    = iota // int64
+	kindFloat         // float64
+	kindString        // string
+	kindBool          // bool
+	kindTime          // int64 milliseconds since epoch
+)
+
+type column struct {
+	name string
+	kind int
+}
+
+// table is the schema of an output file
+type table struct {
+	name    string
+	columns []column
+}
+
+var tradesTable = &table{
+	name: "trades",
+	columns: []column{
+		{"timestamp", kindTime},
+		{"instrument_name", kindString},
+		{"trade_seq", kindInt},
+		{"trade_id", kindString},
+		{"price", kindFloat},
+		{"amount", kindFloat},
+		{"direction", kindString},
+		{"tick_direction", kindInt},
+		{"index_price", kindFloat},
+		{"iv", kindFloat},
+		{"backfill", kindBool},
+	},
+}
+
+var bookTable = &table{
+	name: "book",
+	columns: []column{
+		{"timestamp", kindTime},
+		{"instrument_name", kindString},
+		{"change_id", kindInt},
+		{"prev_change_id", kindInt},
+		{"side", kindString},
+		{"action", kindString},
+		{"price", kindFloat},
+		{"amount", kindFloat},
+	},
+}
+
+var tickerTable = &table{
+	name: "ticker",
+	columns: []column{
+		{"timestamp", kindTime},
+		{"instrument_name", kindString},
+		{"state", kindString},
+		{"best_bid_price", kindFloat},
+		{"best_bid_amount", kindFloat},
+		{"best_ask_price", kindFloat},
+		{"best_ask_amount", kindFloat},
+		{"last_price", kindFloat},
+		{"mark_price", kindFloat},
+		{"index_price", kindFloat},
+		{"settlement_price", kindFloat},
+		{"open_interest", kindFloat},
+		{"min_price", kindFloat},
+		{"max_price", kindFloat},
+		{"current_funding", kindFloat},
+		{"funding_8h", kindFloat},
+		{"volume", kindFloat},
+		{"low", kindFloat},
+		{"high", kindFloat},
+	},
+}
+
+var indexTable = &table{
+	name: "deribit_price_index",
+	columns: []column{
+		{"timestamp", kindTime},
+		{"index_name", kindString},
+		{"price", kindFloat},
+	},
+}
+
+var tables = []*table{tradesTable, bookTable, tickerTable, indexTable}
+
+func tradeRow(t *models.Trade, backfill bool) []interface{} {
+	return []interface{}{
+		t.Timestamp,
+		t.InstrumentName,
+		int64(t.TradeSeq),
+		t.TradeID,
+		t.Price,
+		t.Amount,
+		string(t.Direction),
+		int64(t.TickDirection),
+		t.IndexPrice,
+		t.Iv,
+		backfill,
+	}
+}
+
+func bookRow(timestamp int64, instrument string, changeID int64, prevChangeID int64, side string, action string, price float64, amount float64) []interface{} {
+	return []interface{}{timestamp, instrument, changeID, prevChangeID, side, action, price, amount}
+}
+
+func tickerRow(t *models.TickerNotification) []interface{} {
+	return []interface{}{
+		t.Timestamp,
+		t.InstrumentName,
+		t.State,
+		t.BestBidPrice,
+		t.BestBidAmount,
+		t.BestAskPrice,
+		t.BestAskAmount,
+		t.LastPrice,
+		t.MarkPrice,
+		t.IndexPrice,
+		t.SettlementPrice,
+		t.OpenInterest,
+		t.MinPrice,
+		t.MaxPrice,
+		t.CurrentFunding,
+		t.Funding8H,
+		t.Stats.Volume,
+		t.Stats.Low,
+		t.Stats.High,
+	}
+}
+
+func indexRow(t *models.DeribitPriceIndexNotification) []interface{} {
+	return []interface{}{t.Timestamp, t.IndexName, t.Price}
+}
+
+// formatValue formats a column value for CSV
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	}
+	return ""
+}
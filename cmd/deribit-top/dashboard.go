@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/models"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Panels the selection moves in
+const (
+	panelPositions = iota
+	panelOrders
+)
+
+// dashboard is the state of the screen, updated by notifications and keys
+type dashboard struct {
+	client     *deribit.Client
+	currency   string
+	instrument string
+	depth      int
+
+	mu        sync.Mutex
+	portfolio models.PortfolioNotification
+	orders    map[string]models.Order
+	positions map[string]models.Position
+	book      models.OrderBookGroupNotification
+	panel     int
+	selected  int
+	prompt    string
+	action    func() string // run when the prompt is confirmed
+	message   string
+
+	redraw chan struct{}
+}
+
+func newDashboard(client *deribit.Client, currency string, instrument string, depth int) *dashboard {
+	return &dashboard{
+		client:     client,
+		currency:   strings.ToUpper(currency),
+		instrument: instrument,
+		depth:      depth,
+		orders:     make(map[string]models.Order),
+		positions:  make(map[string]models.Position),
+		redraw:     make(chan struct{}, 1),
+	}
+}
+
+// channels returns the channels the dashboard subscribes to
+func (d *dashboard) channels() []string {
+	return []string{
+		"user.portfolio." + strings.ToLower(d.currency),
+		"user.orders.any." + d.currency + ".raw",
+		"user.changes.any." + d.currency + ".raw",
+		fmt.Sprintf("book.%v.none.%v.100ms", d.instrument, d.depth),
+	}
+}
+
+// start loads the open orders and positions, then subscribes
+func (d *dashboard) start() error {
+	orders, err := d.client.GetOpenOrdersByCurrency(&models.GetOpenOrdersByCurrencyParams{Currency: d.currency})
+	if err != nil {
+		return err
+	}
+	positions, err := d.client.GetPositions(&models.GetPositionsParams{Currency: d.currency})
+	if err != nil {
+		return err
+	}
+	d.updateOrders(orders)
+	d.updatePositions(positions)
+
+	channels := d.channels()
+	d.client.On(channels[0], func(e *models.PortfolioNotification) {
+		d.mu.Lock()
+		d.portfolio = *e
+		d.mu.Unlock()
+		d.changed()
+	})
+	d.client.On(channels[1], func(e *models.UserOrderNotification) {
+		d.updateOrders(*e)
+	})
+	d.client.On(channels[2], func(e *models.UserChangesNotification) {
+		d.updateOrders(e.Orders)
+		d.updatePositions(e.Positions)
+	})
+	d.client.On(channels[3], func(e *models.OrderBookGroupNotification) {
+		d.mu.Lock()
+		d.book = *e
+		d.mu.Unlock()
+		d.changed()
+	})
+	d.client.Subscribe(channels)
+	return nil
+}
+
+// changed requests a redraw
+func (d *dashboard) changed() {
+	select {
+	case d.redraw <- struct{}{}:
+	default:
+	}
+}
+
+func (d *dashboard) updateOrders(orders []models.Order) {
+	d.mu.Lock()
+	for _, order := range orders {
+		if order.OrderState == models.OrderStateOpen || order.OrderState == models.OrderStateUntriggered {
+			d.orders[order.OrderID] = order
+		} else {
+			delete(d.orders, order.OrderID)
+		}
+	}
+	d.mu.Unlock()
+	d.changed()
+}
+
+func (d *dashboard) updatePositions(positions []models.Position) {
+	d.mu.Lock()
+	for _, position := range positions {
+		if position.Size == 0 {
+			delete(d.positions, position.InstrumentName)
+		} else {
+			d.positions[position.InstrumentName] = position
+		}
+	}
+	d.mu.Unlock()
+	d.changed()
+}
+
+func (d *dashboard) sortedOrders() []models.Order {
+	var orders []models.Order
+	for _, order := range d.orders {
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].CreationTimestamp != orders[j].CreationTimestamp {
+			return orders[i].CreationTimestamp < orders[j].CreationTimestamp
+		}
+		return orders[i].OrderID < orders[j].OrderID
+	})
+	return orders
+}
+
+func (d *dashboard) sortedPositions() []models.Position {
+	var positions []models.Position
+	for _, position := range d.positions {
+		positions = append(positions, position)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].InstrumentName < positions[j].InstrumentName
+	})
+	return positions
+}
+
+// handleKey handles a key, and reports whether the dashboard should quit
+func (d *dashboard) handleKey(key string) bool {
+	d.mu.Lock()
+	if d.action != nil {
+		action := d.action
+		d.action, d.prompt = nil, ""
+		d.mu.Unlock()
+		if key == "y" {
+			message := action()
+			d.mu.Lock()
+			d.message = message
+			d.mu.Unlock()
+		}
+		d.changed()
+		return false
+	}
+	defer d.changed()
+	defer d.mu.Unlock()
+
+	rows := len(d.positions)
+	if d.panel == panelOrders {
+		rows = len(d.orders)
+	}
+	switch key {
+	case "q", "ctrl-c":
+		return true
+	case "tab":
+		d.panel = 1 - d.panel
+		d.selected = 0
+	case "up", "k":
+		if d.selected > 0 {
+			d.selected--
+		}
+	case "down", "j":
+		if d.selected < rows-1 {
+			d.selected++
+		}
+	case "c":
+		orders := d.sortedOrders()
+		if d.panel != panelOrders || d.selected >= len(orders) {
+			return false
+		}
+		order := orders[d.selected]
+		d.prompt = fmt.Sprintf("Cancel %v %v %v @ %v? (y/n)", order.Direction, order.Amount, order.InstrumentName, order.Price)
+		d.action = func() string {
+			if _, err := d.client.Cancel(&models.CancelParams{OrderID: order.OrderID}); err != nil {
+				return fmt.Sprintf("cancel %v: %v", order.OrderID, err)
+			}
+			return fmt.Sprintf("cancelled %v", order.OrderID)
+		}
+	case "f":
+		positions := d.sortedPositions()
+		if d.panel != panelPositions || d.selected >= len(positions) {
+			return false
+		}
+		position := positions[d.selected]
+		d.prompt = fmt.Sprintf("Flatten %v %v at market? (y/n)", position.Size, position.InstrumentName)
+		d.action = func() string {
+			_, err := d.client.ClosePosition(&models.ClosePositionParams{
+				InstrumentName: position.InstrumentName,
+				Type:           models.OrderTypeMarket,
+			})
+			if err != nil {
+				return fmt.Sprintf("close %v: %v", position.InstrumentName, err)
+			}
+			return fmt.Sprintf("closed %v", position.InstrumentName)
+		}
+	}
+	return false
+}
+
+// ANSI escape sequences
+const (
+	ansiReverse = "\x1b[7m"
+	ansiBold    = "\x1b[1m"
+	ansiReset   = "\x1b[0m"
+)
+
+// render returns the screen
+func (d *dashboard) render(now time.Time) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%vderibit-top%v  %v  %v  %v\n\n", ansiBold, ansiReset, d.currency, d.instrument, now.Format("15:04:05"))
+	p := d.portfolio
+	fmt.Fprintf(&b, "Equity %.4f  Balance %.4f  Available %.4f  Total PnL %.4f\n", p.Equity, p.Balance, p.AvailableFunds, p.TotalPl)
+	fmt.Fprintf(&b, "Margin balance %.4f  Initial margin %.4f  Maintenance margin %.4f  Delta %.4f\n\n", p.MarginBalance, p.InitialMargin, p.MaintenanceMargin, p.DeltaTotal)
+
+	var rows []string
+	for _, position := range d.sortedPositions() {
+		rows = append(rows, fmt.Sprintf("%v\t%v\t%.2f\t%.2f\t%.2f\t%.6f\t%.6f",
+			position.InstrumentName, position.Size, position.AveragePrice, position.MarkPrice,
+			position.EstimatedLiquidationPrice, position.FloatingProfitLoss, position.TotalProfitLoss))
+	}
+	d.renderTable(&b, "Positions", panelPositions, "instrument\tsize\tavg price\tmark\tliq price\tfloating pnl\ttotal pnl", rows)
+
+	rows = nil
+	for _, order := range d.sortedOrders() {
+		rows = append(rows, fmt.Sprintf("%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v",
+			order.OrderID, order.InstrumentName, order.Direction, order.OrderType,
+			order.Price, order.Amount, order.FilledAmount, order.OrderState))
+	}
+	d.renderTable(&b, "Open orders", panelOrders, "id\tinstrument\tside\ttype\tprice\tamount\tfilled\tstate", rows)
+
+	fmt.Fprintf(&b, "%vBook %v%v\n", ansiBold, d.instrument, ansiReset)
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', tabwriter.AlignRight)
+	for i := len(d.book.Asks) - 1; i >= 0; i-- {
+		if len(d.book.Asks[i]) == 2 {
+			fmt.Fprintf(tw, "\t%v\t%v\t\n", d.book.Asks[i][0], d.book.Asks[i][1])
+		}
+	}
+	for _, level := range d.book.Bids {
+		if len(level) == 2 {
+			fmt.Fprintf(tw, "%v\t%v\t\t\n", level[1], level[0])
+		}
+	}
+	tw.Flush()
+
+	b.WriteString("\n")
+	switch {
+	case d.prompt != "":
+		fmt.Fprintf(&b, "%v%v%v\n", ansiReverse, d.prompt, ansiReset)
+	case d.message != "":
+		fmt.Fprintf(&b, "%v\n", d.message)
+	}
+	b.WriteString("tab switch panel  j/k select  c cancel order  f flatten position  q quit\n")
+	return b.String()
+}
+
+func (d *dashboard) renderTable(b *bytes.Buffer, title string, panel int, header string, rows []string) {
+	fmt.Fprintf(b, "%v%v%v\n", ansiBold, title, ansiReset)
+	var t bytes.Buffer
+	tw := tabwriter.NewWriter(&t, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  "+header)
+	for _, row := range rows {
+		fmt.Fprintln(tw, "  "+row)
+	}
+	tw.Flush()
+	for i, line := range strings.Split(strings.TrimSuffix(t.String(), "\n"), "\n") {
+		if i > 0 && d.panel == panel && i-1 == d.selected {
+			line = ansiReverse + ">" + line[1:] + ansiReset
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// eventually waits for cond
+func eventually(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SeedBook("BTC-PERPETUAL", [][]float64{{5999, 10}}, [][]float64{{6001, 100}})
+
+	client := deribit.New(&deribit.Configuration{Addr: server.URL, ApiKey: "key", SecretKey: "secret"})
+	_, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6001})
+	assert.Nil(t, err)
+
+	d := newDashboard(client, "btc", "BTC-PERPETUAL", 10)
+	assert.Nil(t, d.start())
+	assert.True(t, server.WaitSubscribed("user.changes.any.BTC.raw", time.Second))
+	assert.Contains(t, d.render(time.Now()), "6001.00")
+
+	sell, err := client.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 20, Price: 7000})
+	assert.Nil(t, err)
+	eventually(t, func() bool { return strings.Contains(d.render(time.Now()), sell.Order.OrderID+" ") })
+
+	d.handleKey("tab")
+	d.handleKey("c")
+	assert.Contains(t, d.render(time.Now()), "Cancel sell 20 BTC-PERPETUAL @ 7000? (y/n)")
+	d.handleKey("y")
+	order, _ := server.Order(sell.Order.OrderID)
+	assert.Equal(t, models.OrderStateCancelled, order.OrderState)
+	eventually(t, func() bool { return !strings.Contains(d.render(time.Now()), sell.Order.OrderID+" ") })
+
+	d.handleKey("tab")
+	d.handleKey("f")
+	d.handleKey("n")
+	assert.Equal(t, 10.0, server.Positions()[0].Size)
+	d.handleKey("f")
+	d.handleKey("y")
+	assert.Equal(t, 0.0, server.Positions()[0].Size)
+	eventually(t, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return len(d.positions) == 0
+	})
+
+	assert.True(t, d.handleKey("q"))
+}
+
+func TestDecodeKeys(t *testing.T) {
+	assert.Equal(t, []string{"up", "j", "down", "tab", "esc", "ctrl-c"}, decodeKeys([]byte("\x1b[Aj\x1b[B\t\x1b\x03")))
+}
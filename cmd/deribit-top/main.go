@@ -0,0 +1,83 @@
+// Command deribit-top is a live terminal dashboard of an account.
+//
+// Usage:
+//
+//	deribit-top -currency BTC -instrument BTC-PERPETUAL
+//
+// It shows the equity and margin of the user.portfolio channel, the
+// positions with their PnL and the open orders kept up to date by the
+// user.orders and user.changes channels, and a depth ladder of the
+// instrument. The selected order can be cancelled with c and the selected
+// position flattened at market with f.
+//
+// Credentials are read like the deribit command, from the DERIBIT_API_KEY
+// and DERIBIT_SECRET_KEY environment variables or ~/.deribit.json.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/internal/config"
+	"os"
+	"time"
+)
+
+func main() {
+	configPath := flag.String("config", config.DefaultPath(), "JSON config file")
+	addr := flag.String("addr", "", "WebSocket API address")
+	test := flag.Bool("test", false, "use test.deribit.com")
+	currency := flag.String("currency", "BTC", "currency of the portfolio, orders and positions")
+	instrument := flag.String("instrument", "BTC-PERPETUAL", "instrument of the book")
+	depth := flag.Int("depth", 10, "book depth: 1, 10 or 20")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath, os.Getenv)
+	if err != nil {
+		fatal(err)
+	}
+	if *addr != "" {
+		cfg.Addr = *addr
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = config.BaseURL(*test, false)
+	}
+	if cfg.ApiKey == "" || cfg.SecretKey == "" {
+		fatal(deribit.ErrAuthenticationIsRequired)
+	}
+	cfg.AutoReconnect = true
+
+	d := newDashboard(deribit.New(cfg), *currency, *instrument, *depth)
+	if err := d.start(); err != nil {
+		fatal(err)
+	}
+
+	restore, err := makeRaw(os.Stdout)
+	if err != nil {
+		fatal(err)
+	}
+	defer restore()
+
+	keys := make(chan string, 16)
+	go readKeys(os.Stdin, keys)
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+
+	draw(os.Stdout, d.render(time.Now()))
+	for {
+		select {
+		case key, ok := <-keys:
+			if !ok || d.handleKey(key) {
+				return
+			}
+		case <-d.redraw:
+		case <-tick.C:
+		}
+		draw(os.Stdout, d.render(time.Now()))
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "deribit-top:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ANSI escape sequences controlling the screen
+const (
+	ansiAltScreen  = "\x1b[?1049h"
+	ansiMainScreen = "\x1b[?1049l"
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+	ansiHome       = "\x1b[H"
+	ansiClear      = "\x1b[2J"
+)
+
+// stty runs stty on the terminal of stdin
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// makeRaw puts the terminal in raw mode on the alternate screen,
+// restore returns to the previous state
+func makeRaw(w io.Writer) (restore func(), err error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	io.WriteString(w, ansiAltScreen+ansiHideCursor)
+	return func() {
+		io.WriteString(w, ansiShowCursor+ansiMainScreen)
+		stty(state)
+	}, nil
+}
+
+// draw replaces the screen by s, raw mode needs \r\n line endings
+func draw(w io.Writer, s string) {
+	io.WriteString(w, ansiHome+ansiClear+strings.Replace(s, "\n", "\r\n", -1))
+}
+
+// readKeys sends the keys read from r until it fails
+func readKeys(r io.Reader, keys chan<- string) {
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, key := range decodeKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
+
+// decodeKeys returns the keys of the input bytes, arrows and control keys by name
+func decodeKeys(b []byte) []string {
+	var keys []string
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == 0x1b && i+2 < len(b) && b[i+1] == '[':
+			switch b[i+2] {
+			case 'A':
+				keys = append(keys, "up")
+			case 'B':
+				keys = append(keys, "down")
+			}
+			i += 2
+		case b[i] == 0x1b:
+			keys = append(keys, "esc")
+		case b[i] == 3:
+			keys = append(keys, "ctrl-c")
+		case b[i] == '\t':
+			keys = append(keys, "tab")
+		default:
+			keys = append(keys, string(b[i]))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/frankrap/deribit-api"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// command is an API method of deribit.Client
+type command struct {
+	name   string
+	method reflect.Method
+	params reflect.Type // nil if the method takes no params
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// commands returns the API methods of deribit.Client by name,
+// the names of Get methods are also registered without "get-"
+func commands() map[string]*command {
+	t := reflect.TypeOf(&deribit.Client{})
+	m := make(map[string]*command)
+	for i := 0; i < t.NumMethod(); i++ {
+		method := t.Method(i)
+		mt := method.Type
+		if mt.NumOut() != 2 || mt.Out(1) != errorType {
+			continue
+		}
+		cmd := &command{name: kebab(method.Name), method: method}
+		switch mt.NumIn() {
+		case 1:
+		case 2:
+			in := mt.In(1)
+			if in.Kind() != reflect.Ptr || in.Elem().Kind() != reflect.Struct ||
+				!strings.HasSuffix(in.Elem().PkgPath(), "/models") {
+				continue
+			}
+			cmd.params = in.Elem()
+		default:
+			continue
+		}
+		m[cmd.name] = cmd
+	}
+	for name, cmd := range m {
+		alias := strings.TrimPrefix(name, "get-")
+		if _, ok := m[alias]; !ok {
+			m[alias] = cmd
+		}
+	}
+	return m
+}
+
+// commandNames returns the sorted names of the commands, without aliases
+func commandNames(m map[string]*command) []string {
+	var names []string
+	for name, cmd := range m {
+		if name == cmd.name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// kebab converts a method name to a command name, e.g. GetOrderBook to get-order-book
+// and CancelTransferByID to cancel-transfer-by-id
+func kebab(name string) string {
+	runes := []rune(strings.Replace(name, "IDs", "Ids", -1))
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// param is a field of a params struct
+type param struct {
+	name  string // JSON name
+	index int
+}
+
+func paramsOf(t reflect.Type) []param {
+	var params []param
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || f.PkgPath != "" {
+			continue
+		}
+		params = append(params, param{name: name, index: i})
+	}
+	return params
+}
+
+// usage returns the arguments of the command
+func (c *command) usage() string {
+	if c.params == nil {
+		return c.name
+	}
+	var args []string
+	for _, p := range paramsOf(c.params) {
+		t := c.params.Field(p.index).Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		args = append(args, fmt.Sprintf("[--%v %v]", p.name, t))
+	}
+	return c.name + " " + strings.Join(args, " ")
+}
+
+// parseParams parses args into a new params struct, positional arguments set
+// the fields in order and --name value or --name=value set the field by its
+// JSON name, with dashes or underscores
+func (c *command) parseParams(args []string) (reflect.Value, error) {
+	if c.params == nil {
+		if len(args) > 0 {
+			return reflect.Value{}, fmt.Errorf("%v takes no arguments", c.name)
+		}
+		return reflect.Value{}, nil
+	}
+	v := reflect.New(c.params)
+	params := paramsOf(c.params)
+	positional := 0
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			if positional >= len(params) {
+				return v, fmt.Errorf("too many arguments: %v", arg)
+			}
+			if err := setField(v.Elem().Field(params[positional].index), arg); err != nil {
+				return v, fmt.Errorf("%v: %v", params[positional].name, err)
+			}
+			positional++
+			continue
+		}
+		name := strings.TrimPrefix(arg, "--")
+		var value string
+		hasValue := false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+		name = strings.Replace(name, "-", "_", -1)
+		p, ok := findParam(params, name)
+		if !ok {
+			return v, fmt.Errorf("unknown flag --%v", name)
+		}
+		field := v.Elem().Field(p.index)
+		if !hasValue {
+			if field.Kind() == reflect.Bool && (i+1 >= len(args) || !isBool(args[i+1])) {
+				value = "true"
+			} else if i+1 < len(args) {
+				i++
+				value = args[i]
+			} else {
+				return v, fmt.Errorf("--%v requires a value", name)
+			}
+		}
+		if err := setField(field, value); err != nil {
+			return v, fmt.Errorf("--%v: %v", name, err)
+		}
+	}
+	return v, nil
+}
+
+func findParam(params []param, name string) (param, bool) {
+	for _, p := range params {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return param{}, false
+}
+
+func isBool(s string) bool {
+	_, err := strconv.ParseBool(s)
+	return err == nil
+}
+
+func setField(field reflect.Value, s string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return errors.New("unsupported type")
+		}
+		field.Set(reflect.ValueOf(strings.Split(s, ",")))
+	case reflect.Ptr:
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), s); err != nil {
+			return err
+		}
+		field.Set(elem)
+	default:
+		return errors.New("unsupported type")
+	}
+	return nil
+}
+
+// call calls the command on client
+func (c *command) call(client *deribit.Client, args []string) (interface{}, error) {
+	params, err := c.parseParams(args)
+	if err != nil {
+		return nil, err
+	}
+	in := []reflect.Value{reflect.ValueOf(client)}
+	if c.params != nil {
+		in = append(in, params)
+	}
+	out := c.method.Func.Call(in)
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, err
+	}
+	return out[0].Interface(), nil
+}
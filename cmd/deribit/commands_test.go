@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestKebab(t *testing.T) {
+	assert.Equal(t, "get-order-book", kebab("GetOrderBook"))
+	assert.Equal(t, "cancel-transfer-by-id", kebab("CancelTransferByID"))
+	assert.Equal(t, "get-order-margin-by-ids", kebab("GetOrderMarginByIDs"))
+}
+
+func TestCommand_ParseParams(t *testing.T) {
+	m := commands()
+	assert.Equal(t, m["get-positions"], m["positions"])
+	assert.Nil(t, m["call"])
+
+	v, err := m["buy"].parseParams([]string{"BTC-PERPETUAL", "10", "--type=limit", "--price", "6000", "--post-only", "--max_show", "5"})
+	assert.Nil(t, err)
+	params := v.Interface().(*models.BuyParams)
+	assert.Equal(t, "BTC-PERPETUAL", params.InstrumentName)
+	assert.Equal(t, 10.0, params.Amount)
+	assert.Equal(t, models.OrderTypeLimit, params.Type)
+	assert.Equal(t, 6000.0, params.Price)
+	assert.True(t, params.PostOnly)
+	assert.Equal(t, 5.0, *params.MaxShow)
+
+	_, err = m["buy"].parseParams([]string{"--unknown", "1"})
+	assert.NotNil(t, err)
+	_, err = m["buy"].parseParams([]string{"BTC-PERPETUAL", "ten"})
+	assert.NotNil(t, err)
+	_, err = m["time"].parseParams([]string{"1"})
+	assert.NotNil(t, err)
+}
+
+func TestCommand_Call(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SeedBook("BTC-PERPETUAL", [][]float64{{5999, 10}}, [][]float64{{6001, 20}})
+
+	client := deribit.New(&deribit.Configuration{Addr: server.HTTPURL, ApiKey: "key", SecretKey: "secret"})
+	m := commands()
+	result, err := m["buy"].call(client, []string{"BTC-PERPETUAL", "10", "--price", "6001"})
+	assert.Nil(t, err)
+	assert.Equal(t, models.OrderStateFilled, result.(models.BuyResponse).Order.OrderState)
+
+	result, err = m["positions"].call(client, []string{"--currency", "BTC"})
+	assert.Nil(t, err)
+	var buf bytes.Buffer
+	assert.Nil(t, printTable(&buf, result))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[1], "BTC-PERPETUAL")
+
+	result, err = m["order-book"].call(client, []string{"BTC-PERPETUAL"})
+	assert.Nil(t, err)
+	buf.Reset()
+	assert.Nil(t, printTable(&buf, result))
+	assert.Regexp(t, `best_ask_price +6001\n`, buf.String())
+
+	_, err = m["cancel"].call(client, []string{"missing"})
+	assert.NotNil(t, err)
+}
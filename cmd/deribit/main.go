@@ -0,0 +1,148 @@
+// Command deribit calls the Deribit API from the command line.
+//
+// Usage:
+//
+//	deribit [flags] command [arguments]
+//
+// Every API method of deribit.Client is a command named after it, e.g.
+// GetOrderBook is get-order-book, and Get methods can be called without
+// "get-". Positional arguments set the params in order, flags set them by
+// their JSON name:
+//
+//	deribit ticker BTC-PERPETUAL
+//	deribit positions --currency BTC --kind future
+//	deribit buy BTC-PERPETUAL 10 --type limit --price 6000 --post-only
+//	deribit subscribe trades.BTC-PERPETUAL.raw book.BTC-PERPETUAL.100ms
+//
+// Credentials are read from the DERIBIT_API_KEY and DERIBIT_SECRET_KEY
+// environment variables or the JSON config file (~/.deribit.json by default)
+// with the fields of deribit.Configuration, e.g. {"api_key":"...","secret_key":"..."}.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/internal/config"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+func main() {
+	configPath := flag.String("config", config.DefaultPath(), "JSON config file")
+	addr := flag.String("addr", "", "API address, defaults to the HTTP API or the WebSocket API for subscribe")
+	test := flag.Bool("test", false, "use test.deribit.com")
+	output := flag.String("output", outputJSON, "output format: json or table")
+	debug := flag.Bool("debug", false, "debug mode")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath, os.Getenv)
+	if err != nil {
+		fatal(err)
+	}
+	if *addr != "" {
+		cfg.Addr = *addr
+	}
+	cfg.DebugMode = cfg.DebugMode || *debug
+
+	name, args := flag.Arg(0), flag.Args()[1:]
+	switch name {
+	case "help":
+		usage()
+		return
+	case "subscribe":
+		if cfg.Addr == "" {
+			cfg.Addr = config.BaseURL(*test, false)
+		}
+		if err := subscribe(cfg, args, os.Stdout); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	cmd, ok := commands()[name]
+	if !ok {
+		fatal(fmt.Errorf("unknown command %q, see deribit help", name))
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = config.BaseURL(*test, true)
+	}
+	result, err := cmd.call(deribit.New(cfg), args)
+	if err != nil {
+		fatal(err)
+	}
+	switch *output {
+	case outputTable:
+		err = printTable(os.Stdout, result)
+	default:
+		err = printJSON(os.Stdout, result)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: deribit [flags] command [arguments]\n\nFlags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(w, "\nCommands:\n  subscribe channel...\n")
+	m := commands()
+	for _, name := range commandNames(m) {
+		fmt.Fprintf(w, "  %v\n", m[name].usage())
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "deribit:", err)
+	os.Exit(1)
+}
+
+// subscribe prints the notifications of channels as JSON lines until interrupted
+func subscribe(cfg *deribit.Configuration, channels []string, w io.Writer) error {
+	if len(channels) == 0 {
+		return fmt.Errorf("subscribe requires channels")
+	}
+	if !strings.HasPrefix(cfg.Addr, "ws") {
+		return fmt.Errorf("subscribe requires a WebSocket address, got %v", cfg.Addr)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	lines := make(chan []byte, 1024)
+	client := deribit.New(cfg)
+	for _, channel := range channels {
+		channel := channel
+		client.On(channel, func(data interface{}) {
+			line, _ := json.Marshal(&struct {
+				Channel string      `json:"channel"`
+				Data    interface{} `json:"data"`
+			}{channel, data})
+			lines <- line
+		})
+	}
+	client.Subscribe(channels)
+	for {
+		select {
+		case line := <-lines:
+			fmt.Fprintf(w, "%s\n", line)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
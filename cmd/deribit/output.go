@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printTable prints a slice of structs as a table with a row per element,
+// a struct as name and value rows followed by tables for its slices of
+// structs, and anything else as is
+func printTable(w io.Writer, v interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	switch {
+	case isStructSlice(rv.Type()):
+		writeRows(tw, rv)
+	case rv.Kind() == reflect.Struct:
+		var tables []reflect.Value
+		var names []string
+		for _, c := range cells(rv, "") {
+			fmt.Fprintf(tw, "%v\t%v\n", c.name, c.value)
+		}
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			if f.PkgPath == "" && isStructSlice(f.Type) {
+				tables = append(tables, rv.Field(i))
+				names = append(names, jsonName(f))
+			}
+		}
+		for i, t := range tables {
+			tw.Flush()
+			fmt.Fprintf(w, "\n%v:\n", names[i])
+			writeRows(tw, t)
+		}
+	default:
+		fmt.Fprintln(tw, rv.Interface())
+	}
+	return tw.Flush()
+}
+
+func writeRows(w io.Writer, v reflect.Value) {
+	for i := 0; i < v.Len(); i++ {
+		row := cells(reflect.Indirect(v.Index(i)), "")
+		if i == 0 {
+			var names []string
+			for _, c := range row {
+				names = append(names, c.name)
+			}
+			fmt.Fprintln(w, strings.Join(names, "\t"))
+		}
+		var values []string
+		for _, c := range row {
+			values = append(values, c.value)
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+}
+
+type cell struct {
+	name  string
+	value string
+}
+
+// cells flattens the fields of a struct, nested structs are prefixed by their
+// name and slices of structs are left out
+func cells(v reflect.Value, prefix string) []cell {
+	var result []cell
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.PkgPath != "" || isStructSlice(f.Type) {
+			continue
+		}
+		name := prefix + jsonName(f)
+		fv := v.Field(i)
+		switch {
+		case fv.Kind() == reflect.Struct:
+			result = append(result, cells(fv, name+".")...)
+		case fv.Kind() == reflect.Ptr && fv.IsNil():
+			result = append(result, cell{name, ""})
+		case fv.Kind() == reflect.Slice || fv.Kind() == reflect.Map:
+			data, _ := json.Marshal(fv.Interface())
+			result = append(result, cell{name, string(data)})
+		default:
+			result = append(result, cell{name, fmt.Sprint(reflect.Indirect(fv).Interface())})
+		}
+	}
+	return result
+}
+
+func isStructSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	t = t.Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+func jsonName(f reflect.StructField) string {
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return f.Name
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, "GetOrderMarginByIDs", camel("get_order_margin_by_ids"))
+	assert.Equal(t, "CancelTransferByID", camel("cancel_transfer_by_id"))
+	assert.Equal(t, "Interest8H", camel("interest_8h"))
+	assert.Equal(t, "get_order_margin_by_ids_params", snake("GetOrderMarginByIDsParams"))
+	assert.Equal(t, "get_tradingview_chart_data_response", snake("GetTradingviewChartDataResponse"))
+	assert.Equal(t, "cancel_transfer_by_id_params", snake("CancelTransferByIDParams"))
+}
+
+func TestGenerate_UpToDate(t *testing.T) {
+	spec, err := LoadSpec("deribit_api.json")
+	if !assert.Nil(t, err) {
+		return
+	}
+	files, err := generate(spec)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for name, src := range files {
+		existing, err := ioutil.ReadFile(filepath.Join("..", "..", filepath.FromSlash(name)))
+		if assert.Nil(t, err, name) {
+			assert.Equal(t, string(existing), string(src), "%v is out of date, run go generate", name)
+		}
+	}
+}
+
+func TestSpec_Version(t *testing.T) {
+	spec, err := LoadSpec("deribit_api.json")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, models.APIVersion, spec.Version, "the specification and the models follow the same API version")
+	methods := spec.methods()
+	assert.Contains(t, methods, "private/buy")
+	assert.True(t, sort.StringsAreSorted(methods), "methods are sorted")
+}
+
+func TestSpec_Validate(t *testing.T) {
+	spec := &Spec{Sections: []Section{{Name: "Trading", File: "api_trading.go", Methods: []Method{
+		{Method: "admin/buy", Result: Type{Type: "string"}},
+	}}}}
+	assert.NotNil(t, spec.validate())
+	spec.Sections[0].Methods[0] = Method{Method: "private/buy", Result: Type{Type: "array"}}
+	assert.NotNil(t, spec.validate())
+	spec.Sections[0].Methods[0] = Method{Method: "private/buy", Result: Type{Type: "object", Ref: "Order"}}
+	assert.Nil(t, spec.validate())
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const header = "// Code generated by cmd/gen from cmd/gen/deribit_api.json. DO NOT EDIT.\n\n"
+
+const modelsImport = "github.com/frankrap/deribit-api/models"
+
+// generate returns the formatted source files of spec by path relative to the repository root,
+// the api_*.go files of the Client methods and the models/*.go files of their params and results
+func generate(spec *Spec) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	models := make(map[string][]Field)
+	for _, section := range spec.Sections {
+		var b bytes.Buffer
+		usesModels := false
+		for _, method := range section.Methods {
+			m := method
+			if m.Params != nil {
+				usesModels = true
+				if err := addModel(models, m.paramsType(), m.Params.Fields); err != nil {
+					return nil, err
+				}
+			}
+			if err := collectModels(models, &m.Result); err != nil {
+				return nil, err
+			}
+			if strings.Contains(goType(&m.Result), "models.") {
+				usesModels = true
+			}
+			writeMethod(&b, &m)
+		}
+
+		var src bytes.Buffer
+		src.WriteString(header)
+		src.WriteString("package deribit\n\n")
+		if usesModels {
+			fmt.Fprintf(&src, "import %q\n\n", modelsImport)
+		}
+		src.Write(b.Bytes())
+		if err := addFile(files, section.File, src.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(models))
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var src bytes.Buffer
+		src.WriteString(header)
+		src.WriteString("package models\n\n")
+		writeStruct(&src, name, models[name])
+		if err := addFile(files, path.Join("models", snake(name)+".go"), src.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func addFile(files map[string][]byte, name string, src []byte) error {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("%v: %v", name, err)
+	}
+	files[name] = formatted
+	return nil
+}
+
+// addModel adds the struct name of fields, shared structs must have the same fields everywhere
+func addModel(models map[string][]Field, name string, fields []Field) error {
+	if existing, ok := models[name]; ok {
+		if !reflect.DeepEqual(existing, fields) {
+			return fmt.Errorf("%v: defined twice with different fields", name)
+		}
+		return nil
+	}
+	models[name] = fields
+	return nil
+}
+
+// collectModels adds the inline objects of t
+func collectModels(models map[string][]Field, t *Type) error {
+	switch t.Type {
+	case "array":
+		return collectModels(models, t.Items)
+	case "object":
+		if t.Ref != "" {
+			return nil
+		}
+		if err := addModel(models, t.Name, t.Fields); err != nil {
+			return err
+		}
+		for i := range t.Fields {
+			if err := collectModels(models, &t.Fields[i].Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeMethod(b *bytes.Buffer, m *Method) {
+	name := m.goName()
+	fmt.Fprintf(b, "// %v %v\n", name, lowerFirst(m.Description))
+	if m.Params == nil {
+		fmt.Fprintf(b, "func (c *Client) %v() (result %v, err error) {\n", name, goType(&m.Result))
+		fmt.Fprintf(b, "\terr = c.Call(%q, nil, &result)\n", m.Method)
+	} else {
+		fmt.Fprintf(b, "func (c *Client) %v(params *models.%v) (result %v, err error) {\n", name, m.paramsType(), goType(&m.Result))
+		fmt.Fprintf(b, "\terr = c.Call(%q, params, &result)\n", m.Method)
+	}
+	b.WriteString("\treturn\n}\n\n")
+}
+
+func writeStruct(b *bytes.Buffer, name string, fields []Field) {
+	fmt.Fprintf(b, "type %v struct {\n", name)
+	for i := range fields {
+		f := &fields[i]
+		if f.Description != "" {
+			fmt.Fprintf(b, "\t// %v\n", f.Description)
+		}
+		tag := f.Name
+		if !f.Required {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(b, "\t%v %v `json:%q`\n", f.goName(), strings.Replace(goType(&f.Type), "models.", "", -1), tag)
+	}
+	b.WriteString("}\n")
+}
+
+// goType returns the Go type of t as seen from package deribit
+func goType(t *Type) string {
+	var s string
+	switch t.Type {
+	case "string":
+		s = "string"
+	case "integer":
+		s = "int"
+	case "timestamp":
+		s = "int64"
+	case "number":
+		s = "float64"
+	case "boolean":
+		s = "bool"
+	case "any":
+		return "interface{}"
+	case "array":
+		return "[]" + goType(t.Items)
+	case "enum":
+		s = "models." + t.Ref
+	case "object":
+		if t.Ref != "" {
+			s = "models." + t.Ref
+		} else {
+			s = "models." + t.Name
+		}
+	}
+	if t.Nullable {
+		return "*" + s
+	}
+	return s
+}
+
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
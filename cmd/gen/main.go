@@ -0,0 +1,80 @@
+// Command gen generates the Client methods and their params and result models
+// from the API specification in deribit_api.json.
+//
+// Usage, from the repository root:
+//
+//	go generate
+//	go run ./cmd/gen -check
+//
+// Each section of the specification is written to its api_*.go file, params
+// and inline result objects to models/<type>.go. With -check nothing is
+// written and the files which differ from the specification are listed.
+//
+// deribit_api.json is maintained from the JSON-RPC documentation of the API
+// version it names, which must match models.APIVersion. To refresh it for a
+// new version, diff the method list of the documentation against
+//
+//	go run ./cmd/gen -methods
+//
+// then add, remove or update the methods, params and result fields which changed,
+// set both versions, regenerate and record new responses in models/testdata.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+func main() {
+	specPath := flag.String("spec", "cmd/gen/deribit_api.json", "API specification")
+	dir := flag.String("dir", ".", "repository root")
+	check := flag.Bool("check", false, "list the files which differ from the specification instead of writing them")
+	methods := flag.Bool("methods", false, "list the methods of the specification instead of generating")
+	flag.Parse()
+
+	spec, err := LoadSpec(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *methods {
+		for _, name := range spec.methods() {
+			fmt.Println(name)
+		}
+		return
+	}
+	files, err := generate(spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	stale := 0
+	for _, name := range names {
+		path := filepath.Join(*dir, filepath.FromSlash(name))
+		existing, err := ioutil.ReadFile(path)
+		if err == nil && bytes.Equal(existing, files[name]) {
+			continue
+		}
+		if *check {
+			fmt.Println(name)
+			stale++
+			continue
+		}
+		if err := ioutil.WriteFile(path, files[name], 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if stale > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Spec is the JSON-RPC specification of the API, grouped in sections
+// generated to one api_*.go file each
+type Spec struct {
+	Version  string    `json:"version"`
+	Sections []Section `json:"sections"`
+}
+
+// Section is a group of methods such as "Trading"
+type Section struct {
+	Name    string   `json:"name"`
+	File    string   `json:"file"`
+	Methods []Method `json:"methods"`
+}
+
+// Method is a JSON-RPC method such as "private/buy"
+type Method struct {
+	Method      string  `json:"method"`
+	GoName      string  `json:"go_name,omitempty"`
+	Description string  `json:"description"`
+	Params      *Params `json:"params,omitempty"`
+	Result      Type    `json:"result"`
+}
+
+// Params are the params of a method, Type defaults to the method name followed by Params
+type Params struct {
+	Type   string  `json:"type,omitempty"`
+	Fields []Field `json:"fields"`
+}
+
+// Type is the type of a field or result.
+// Primitive types are string, integer, timestamp (int64), number, boolean and any.
+// Enums Ref a named string type of models, arrays have Items, objects either Ref
+// to a model or a Name and Fields to generate.
+type Type struct {
+	Type     string  `json:"type"`
+	Nullable bool    `json:"nullable,omitempty"`
+	Items    *Type   `json:"items,omitempty"`
+	Ref      string  `json:"ref,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Fields   []Field `json:"fields,omitempty"`
+}
+
+// Field is a field of params or of a generated object, optional fields are omitted when empty
+type Field struct {
+	Name        string `json:"name"`
+	GoName      string `json:"go_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type
+	Required bool `json:"required"`
+}
+
+// LoadSpec reads and validates the spec at path
+func LoadSpec(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	return &spec, nil
+}
+
+func (s *Spec) validate() error {
+	methods := make(map[string]bool)
+	for _, section := range s.Sections {
+		if !strings.HasPrefix(section.File, "api_") || !strings.HasSuffix(section.File, ".go") {
+			return fmt.Errorf("section %v: file %q is not api_*.go", section.Name, section.File)
+		}
+		for _, method := range section.Methods {
+			if methods[method.Method] {
+				return fmt.Errorf("method %v: duplicate", method.Method)
+			}
+			methods[method.Method] = true
+			if method.scope() != "public" && method.scope() != "private" {
+				return fmt.Errorf("method %v: scope is not public or private", method.Method)
+			}
+			if method.Params != nil {
+				if err := validateFields(method.Params.Fields); err != nil {
+					return fmt.Errorf("method %v params: %v", method.Method, err)
+				}
+			}
+			if err := method.Result.validate(); err != nil {
+				return fmt.Errorf("method %v result: %v", method.Method, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateFields(fields []Field) error {
+	names := make(map[string]bool)
+	for _, field := range fields {
+		if names[field.Name] {
+			return fmt.Errorf("duplicate field %v", field.Name)
+		}
+		names[field.Name] = true
+		if err := field.Type.validate(); err != nil {
+			return fmt.Errorf("field %v: %v", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func (t *Type) validate() error {
+	switch t.Type {
+	case "string", "integer", "timestamp", "number", "boolean", "any":
+		return nil
+	case "array":
+		if t.Items == nil {
+			return fmt.Errorf("array without items")
+		}
+		return t.Items.validate()
+	case "enum":
+		if t.Ref == "" {
+			return fmt.Errorf("enum without ref")
+		}
+		return nil
+	case "object":
+		if t.Ref == "" && t.Name == "" {
+			return fmt.Errorf("object without ref or name")
+		}
+		return validateFields(t.Fields)
+	}
+	return fmt.Errorf("unknown type %q", t.Type)
+}
+
+// scope returns "public" or "private", the routing of the method
+func (m *Method) scope() string {
+	return strings.SplitN(m.Method, "/", 2)[0]
+}
+
+// goName returns the name of the Client method
+func (m *Method) goName() string {
+	if m.GoName != "" {
+		return m.GoName
+	}
+	return camel(m.Method[strings.IndexByte(m.Method, '/')+1:])
+}
+
+// paramsType returns the name of the params model
+func (m *Method) paramsType() string {
+	if m.Params.Type != "" {
+		return m.Params.Type
+	}
+	return m.goName() + "Params"
+}
+
+func (f *Field) goName() string {
+	if f.GoName != "" {
+		return f.GoName
+	}
+	return camel(f.Name)
+}
+
+// initialisms are the words of names spelled in capitals
+var initialisms = map[string]string{
+	"api": "API",
+	"id":  "ID",
+	"ids": "IDs",
+	"url": "URL",
+}
+
+// camel returns the Go name of a snake case name, e.g. GetOrderMarginByIDs of get_order_margin_by_ids
+func camel(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if s, ok := initialisms[word]; ok {
+			b.WriteString(s)
+			continue
+		}
+		upper := false
+		for _, r := range word {
+			if !upper && unicode.IsLetter(r) {
+				r = unicode.ToUpper(r)
+				upper = true
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// snake returns the file name of a Go name, e.g. get_order_margin_by_ids_response of GetOrderMarginByIDsResponse
+func snake(name string) string {
+	rs := []rune(strings.Replace(name, "IDs", "Ids", -1))
+	var b strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) &&
+			(!unicode.IsUpper(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// methods returns the sorted names of the methods of s
+func (s *Spec) methods() []string {
+	var names []string
+	for _, section := range s.Sections {
+		for _, method := range section.Methods {
+			names = append(names, method.Method)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,872 @@
+package deribittest
+
+import (
+	"encoding/json"
+	"github.com/frankrap/deribit-api/models"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Error codes returned by the engine, as used by Deribit
+const (
+	ErrCodePostOnlyReject = 11054
+	ErrCodeOtherReject    = 11030
+)
+
+// engine is a price-time priority matching engine holding the user's orders,
+// trades and positions next to seeded liquidity, one book per instrument
+type engine struct {
+	mu        sync.Mutex
+	seq       int64
+	books     map[string]*matchBook
+	orders    map[string]*models.Order
+	triggers  map[string]models.TriggerType
+	positions map[string]*models.Position
+	trades    []models.UserTrade
+	tradeSeq  map[string]int
+}
+
+// matchBook is the book of an instrument
+type matchBook struct {
+	name     string
+	bids     []*entry
+	asks     []*entry
+	stops    []*models.Order
+	last     float64
+	mark     float64
+	index    float64
+	tickSize float64
+	changeID int64
+}
+
+// entry is a resting order, order is nil for seeded liquidity
+type entry struct {
+	order  *models.Order
+	price  float64
+	amount float64
+	seq    int64
+}
+
+// changes collects what an operation changed, to be published afterwards
+type changes struct {
+	orders    []models.Order
+	trades    []models.UserTrade
+	public    []models.Trade
+	positions map[string]struct{}
+}
+
+func newEngine() *engine {
+	return &engine{
+		books:     make(map[string]*matchBook),
+		orders:    make(map[string]*models.Order),
+		triggers:  make(map[string]models.TriggerType),
+		positions: make(map[string]*models.Position),
+		tradeSeq:  make(map[string]int),
+	}
+}
+
+func (e *engine) book(name string) *matchBook {
+	b, ok := e.books[name]
+	if !ok {
+		b = &matchBook{name: name, tickSize: defaultTickSize(name)}
+		e.books[name] = b
+	}
+	return b
+}
+
+// defaultTickSize is the tick size of an instrument until SetTickSize
+func defaultTickSize(name string) float64 {
+	if kindOf(name) == models.KindOption {
+		return 0.0005
+	}
+	return 0.5
+}
+
+func (e *engine) nextSeq() int64 {
+	e.seq++
+	return e.seq
+}
+
+// SeedBook replaces the seeded liquidity of instrument with bids and asks, given as [price, amount] levels.
+// Seeded liquidity is matched against user orders but never reported as user orders.
+func (s *Server) SeedBook(instrumentName string, bids [][]float64, asks [][]float64) {
+	e := s.engine
+	e.mu.Lock()
+	b := e.book(instrumentName)
+	b.bids = removeSeeded(b.bids)
+	b.asks = removeSeeded(b.asks)
+	c := &changes{}
+	for _, level := range bids {
+		e.setLevel(b, models.DirectionBuy, level[0], level[1], c)
+	}
+	for _, level := range asks {
+		e.setLevel(b, models.DirectionSell, level[0], level[1], c)
+	}
+	e.mu.Unlock()
+	s.publishChanges(c)
+}
+
+// ApplyBookChange applies a recorded book.<instrument>.raw notification to the seeded liquidity,
+// user orders crossed by the new levels are filled
+func (s *Server) ApplyBookChange(n models.OrderBookRawNotification) {
+	e := s.engine
+	e.mu.Lock()
+	b := e.book(n.InstrumentName)
+	c := &changes{}
+	for _, item := range n.Bids {
+		amount := item.Amount
+		if item.Action == "delete" {
+			amount = 0
+		}
+		e.setLevel(b, models.DirectionBuy, item.Price, amount, c)
+	}
+	for _, item := range n.Asks {
+		amount := item.Amount
+		if item.Action == "delete" {
+			amount = 0
+		}
+		e.setLevel(b, models.DirectionSell, item.Price, amount, c)
+	}
+	e.mu.Unlock()
+	s.publishChanges(c)
+}
+
+// SetPrices sets the mark and index price of instrument, triggering stop orders
+func (s *Server) SetPrices(instrumentName string, markPrice float64, indexPrice float64) {
+	e := s.engine
+	e.mu.Lock()
+	b := e.book(instrumentName)
+	b.mark = markPrice
+	b.index = indexPrice
+	c := &changes{}
+	e.triggerStops(b, c)
+	e.mu.Unlock()
+	s.publishChanges(c)
+}
+
+// SetTickSize sets the tick size of instrument, used to reprice post only orders
+// just outside the spread. It defaults to 0.0005 for options and 0.5 otherwise.
+func (s *Server) SetTickSize(instrumentName string, tickSize float64) {
+	e := s.engine
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.book(instrumentName).tickSize = tickSize
+}
+
+// Trade records a trade of another participant at price, setting the last price and triggering stop orders
+func (s *Server) Trade(instrumentName string, price float64, amount float64) {
+	e := s.engine
+	e.mu.Lock()
+	b := e.book(instrumentName)
+	c := &changes{}
+	e.publicTrade(b, models.DirectionBuy, price, amount, c)
+	e.triggerStops(b, c)
+	e.mu.Unlock()
+	s.publishChanges(c)
+}
+
+// Orders returns every order placed on the server
+func (s *Server) Orders() []models.Order {
+	e := s.engine
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var orders []models.Order
+	for _, order := range e.orders {
+		orders = append(orders, *order)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTimestamp < orders[j].CreationTimestamp ||
+			(orders[i].CreationTimestamp == orders[j].CreationTimestamp && orderSeq(orders[i]) < orderSeq(orders[j]))
+	})
+	return orders
+}
+
+// Order returns the order with id
+func (s *Server) Order(id string) (models.Order, bool) {
+	e := s.engine
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	order, ok := e.orders[id]
+	if !ok {
+		return models.Order{}, false
+	}
+	return *order, true
+}
+
+// Positions returns the positions of the user
+func (s *Server) Positions() []models.Position {
+	e := s.engine
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var positions []models.Position
+	for _, position := range e.positions {
+		positions = append(positions, *position)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].InstrumentName < positions[j].InstrumentName
+	})
+	return positions
+}
+
+// UserTrades returns the trades of the user
+func (s *Server) UserTrades() []models.UserTrade {
+	e := s.engine
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]models.UserTrade(nil), e.trades...)
+}
+
+func orderSeq(order models.Order) int64 {
+	seq, _ := strconv.ParseInt(order.OrderID, 10, 64)
+	return seq
+}
+
+func removeSeeded(entries []*entry) []*entry {
+	var result []*entry
+	for _, v := range entries {
+		if v.order != nil {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// setLevel sets the seeded amount at price, amount 0 removes the level
+func (e *engine) setLevel(b *matchBook, direction models.Direction, price float64, amount float64, c *changes) {
+	side := &b.bids
+	if direction == models.DirectionSell {
+		side = &b.asks
+	}
+	for i, v := range *side {
+		if v.order == nil && v.price == price {
+			*side = append((*side)[:i], (*side)[i+1:]...)
+			break
+		}
+	}
+	b.changeID++
+	if amount <= 0 {
+		return
+	}
+	incoming := &entry{price: price, amount: amount, seq: e.nextSeq()}
+	e.matchEntry(b, direction, incoming, c)
+	if incoming.amount > 0 {
+		insert(b, direction, incoming)
+	}
+}
+
+// matchEntry matches seeded liquidity against the user orders it crosses
+func (e *engine) matchEntry(b *matchBook, direction models.Direction, incoming *entry, c *changes) {
+	opposite := &b.asks
+	if direction == models.DirectionSell {
+		opposite = &b.bids
+	}
+	for i := 0; i < len(*opposite) && incoming.amount > 0; {
+		resting := (*opposite)[i]
+		if !crosses(direction, incoming.price, resting.price) {
+			break
+		}
+		if resting.order == nil {
+			i++
+			continue
+		}
+		qty := math.Min(incoming.amount, resting.amount)
+		incoming.amount -= qty
+		resting.amount -= qty
+		e.fill(b, resting.order, qty, resting.price, "M", c)
+		e.publicTrade(b, direction, resting.price, qty, c)
+		if resting.amount <= 0 {
+			*opposite = append((*opposite)[:i], (*opposite)[i+1:]...)
+		}
+	}
+	e.triggerStops(b, c)
+}
+
+func insert(b *matchBook, direction models.Direction, v *entry) {
+	if direction == models.DirectionBuy {
+		i := sort.Search(len(b.bids), func(i int) bool {
+			return b.bids[i].price < v.price || (b.bids[i].price == v.price && b.bids[i].seq > v.seq)
+		})
+		b.bids = append(b.bids, nil)
+		copy(b.bids[i+1:], b.bids[i:])
+		b.bids[i] = v
+	} else {
+		i := sort.Search(len(b.asks), func(i int) bool {
+			return b.asks[i].price > v.price || (b.asks[i].price == v.price && b.asks[i].seq > v.seq)
+		})
+		b.asks = append(b.asks, nil)
+		copy(b.asks[i+1:], b.asks[i:])
+		b.asks[i] = v
+	}
+	b.changeID++
+}
+
+func remove(b *matchBook, order *models.Order) bool {
+	for _, side := range []*[]*entry{&b.bids, &b.asks} {
+		for i, v := range *side {
+			if v.order == order {
+				*side = append((*side)[:i], (*side)[i+1:]...)
+				b.changeID++
+				return true
+			}
+		}
+	}
+	for i, v := range b.stops {
+		if v == order {
+			b.stops = append(b.stops[:i], b.stops[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// crosses reports whether an order in direction at price trades with a resting order at restingPrice,
+// price 0 is a market order
+func crosses(direction models.Direction, price float64, restingPrice float64) bool {
+	if price == 0 {
+		return true
+	}
+	if direction == models.DirectionBuy {
+		return price >= restingPrice
+	}
+	return price <= restingPrice
+}
+
+// available returns the amount an order in direction at price can fill immediately
+func available(b *matchBook, direction models.Direction, price float64) float64 {
+	opposite := b.asks
+	if direction == models.DirectionSell {
+		opposite = b.bids
+	}
+	total := 0.0
+	for _, v := range opposite {
+		if !crosses(direction, price, v.price) {
+			break
+		}
+		total += v.amount
+	}
+	return total
+}
+
+// postOnlyPrice returns price moved just outside the spread when an order in direction
+// at price would take liquidity, as the exchange reprices post only orders
+func postOnlyPrice(b *matchBook, direction models.Direction, price float64) float64 {
+	if available(b, direction, price) == 0 {
+		return price
+	}
+	if direction == models.DirectionBuy {
+		return b.asks[0].price - b.tickSize
+	}
+	return b.bids[0].price + b.tickSize
+}
+
+func isOpen(order *models.Order) bool {
+	return order.OrderState == models.OrderStateOpen || order.OrderState == models.OrderStateUntriggered
+}
+
+func isMarket(order *models.Order) bool {
+	return order.OrderType == models.OrderTypeMarket || order.OrderType == models.OrderTypeStopMarket
+}
+
+// execute matches an open order and rests, cancels or fills what remains
+func (e *engine) execute(b *matchBook, order *models.Order, c *changes) {
+	price := float64(order.Price)
+	if isMarket(order) {
+		price = 0
+	}
+	remaining := order.Amount - order.FilledAmount
+	if order.TimeInForce == models.TimeInForceFillOrKill && available(b, order.Direction, price) < remaining {
+		order.OrderState = models.OrderStateCancelled
+		e.changed(order, c)
+		return
+	}
+
+	opposite := &b.asks
+	if order.Direction == models.DirectionSell {
+		opposite = &b.bids
+	}
+	for len(*opposite) > 0 && remaining > 0 {
+		resting := (*opposite)[0]
+		if !crosses(order.Direction, price, resting.price) {
+			break
+		}
+		qty := math.Min(remaining, resting.amount)
+		remaining -= qty
+		resting.amount -= qty
+		if resting.order != nil {
+			e.fill(b, resting.order, qty, resting.price, "M", c)
+		}
+		e.fill(b, order, qty, resting.price, "T", c)
+		e.publicTrade(b, order.Direction, resting.price, qty, c)
+		if resting.amount <= 0 {
+			*opposite = (*opposite)[1:]
+			b.changeID++
+		}
+	}
+
+	switch {
+	case remaining <= 0:
+	case isMarket(order) || order.TimeInForce == models.TimeInForceImmediateOrCancel || order.TimeInForce == models.TimeInForceFillOrKill:
+		order.OrderState = models.OrderStateCancelled
+	default:
+		insert(b, order.Direction, &entry{order: order, price: price, amount: remaining, seq: e.nextSeq()})
+	}
+	e.changed(order, c)
+}
+
+// fill executes qty of order at price
+func (e *engine) fill(b *matchBook, order *models.Order, qty float64, price float64, liquidity string, c *changes) {
+	order.AveragePrice = (order.AveragePrice*order.FilledAmount + price*qty) / (order.FilledAmount + qty)
+	order.FilledAmount += qty
+	if order.FilledAmount >= order.Amount {
+		order.OrderState = models.OrderStateFilled
+	}
+	order.LastUpdateTimestamp = now()
+	e.changed(order, c)
+
+	e.tradeSeq[b.name]++
+	trade := models.UserTrade{
+		TradeSeq:       e.tradeSeq[b.name],
+		TradeID:        strconv.FormatInt(e.nextSeq(), 10),
+		Timestamp:      order.LastUpdateTimestamp,
+		State:          order.OrderState,
+		Price:          price,
+		OrderType:      order.OrderType,
+		OrderID:        order.OrderID,
+		Liquidity:      liquidity,
+		InstrumentName: b.name,
+		IndexPrice:     b.index,
+		FeeCurrency:    currencyOf(b.name),
+		Direction:      order.Direction,
+		Amount:         qty,
+	}
+	e.trades = append(e.trades, trade)
+	c.trades = append(c.trades, trade)
+
+	e.updatePosition(b, order.Direction, qty, price)
+	if c.positions == nil {
+		c.positions = make(map[string]struct{})
+	}
+	c.positions[b.name] = struct{}{}
+}
+
+func (e *engine) publicTrade(b *matchBook, direction models.Direction, price float64, amount float64, c *changes) {
+	b.last = price
+	c.public = append(c.public, models.Trade{
+		TradeSeq:       e.tradeSeq[b.name],
+		TradeID:        strconv.FormatInt(e.nextSeq(), 10),
+		Timestamp:      now(),
+		Price:          price,
+		InstrumentName: b.name,
+		IndexPrice:     b.index,
+		Direction:      direction,
+		Amount:         amount,
+	})
+}
+
+func (e *engine) updatePosition(b *matchBook, direction models.Direction, qty float64, price float64) {
+	position, ok := e.positions[b.name]
+	if !ok {
+		position = &models.Position{
+			InstrumentName: b.name,
+			Kind:           kindOf(b.name),
+		}
+		e.positions[b.name] = position
+	}
+	signed := qty
+	if direction == models.DirectionSell {
+		signed = -qty
+	}
+	size := position.Size + signed
+	switch {
+	case position.Size == 0 || (position.Size > 0) == (signed > 0):
+		// opening or increasing
+		position.AveragePrice = (position.AveragePrice*math.Abs(position.Size) + price*qty) / math.Abs(size)
+	case (position.Size > 0) != (size > 0) && size != 0:
+		// flipped
+		position.AveragePrice = price
+	case size == 0:
+		position.AveragePrice = 0
+	}
+	position.Size = size
+	position.MarkPrice = b.mark
+	position.IndexPrice = b.index
+	switch {
+	case size > 0:
+		position.Direction = models.DirectionBuy
+	case size < 0:
+		position.Direction = models.DirectionSell
+	default:
+		position.Direction = "zero"
+	}
+}
+
+func (e *engine) changed(order *models.Order, c *changes) {
+	for i, v := range c.orders {
+		if v.OrderID == order.OrderID {
+			c.orders[i] = *order
+			return
+		}
+	}
+	c.orders = append(c.orders, *order)
+}
+
+// triggerStops triggers the stop orders of b whose trigger price was reached
+func (e *engine) triggerStops(b *matchBook, c *changes) {
+	for triggered := true; triggered; {
+		triggered = false
+		for _, order := range b.stops {
+			price := b.last
+			switch e.triggers[order.OrderID] {
+			case "mark_price":
+				price = b.mark
+			case "index_price":
+				price = b.index
+			}
+			if price == 0 {
+				continue
+			}
+			if (order.Direction == models.DirectionBuy && price >= order.StopPrice) ||
+				(order.Direction == models.DirectionSell && price <= order.StopPrice) {
+				remove(b, order)
+				order.Triggered = true
+				order.OrderState = models.OrderStateOpen
+				order.LastUpdateTimestamp = now()
+				e.execute(b, order, c)
+				triggered = true
+				break
+			}
+		}
+	}
+}
+
+// place validates and executes a new order
+func (e *engine) place(direction models.Direction, p *models.BuyParams, id string, c *changes) (*models.Order, error) {
+	if p.InstrumentName == "" || p.Amount <= 0 {
+		return nil, Error(ErrCodeInvalidParams, "Invalid params")
+	}
+	if p.Type == "" {
+		p.Type = models.OrderTypeLimit
+	}
+	if p.TimeInForce == "" {
+		p.TimeInForce = models.TimeInForceGoodTilCancelled
+	}
+	switch p.Type {
+	case models.OrderTypeLimit, models.OrderTypeMarket, models.OrderTypeStopLimit, models.OrderTypeStopMarket:
+	default:
+		return nil, Error(ErrCodeInvalidParams, "Invalid params: type")
+	}
+	switch p.TimeInForce {
+	case models.TimeInForceGoodTilCancelled, models.TimeInForceFillOrKill, models.TimeInForceImmediateOrCancel:
+	default:
+		return nil, Error(ErrCodeInvalidParams, "Invalid params: time_in_force")
+	}
+	if (p.Type == models.OrderTypeLimit || p.Type == models.OrderTypeStopLimit) && p.Price <= 0 {
+		return nil, Error(ErrCodeInvalidParams, "Invalid params: price")
+	}
+	if (p.Type == models.OrderTypeStopLimit || p.Type == models.OrderTypeStopMarket) && p.StopPrice <= 0 {
+		return nil, Error(ErrCodeInvalidParams, "Invalid params: stop_price")
+	}
+
+	b := e.book(p.InstrumentName)
+	amount := p.Amount
+	if p.ReduceOnly {
+		size := 0.0
+		if position, ok := e.positions[p.InstrumentName]; ok {
+			size = position.Size
+		}
+		if (direction == models.DirectionBuy && size >= 0) || (direction == models.DirectionSell && size <= 0) {
+			return nil, Error(ErrCodeOtherReject, "reduce_only_reject")
+		}
+		amount = math.Min(amount, math.Abs(size))
+	}
+	if p.PostOnly && p.Type == models.OrderTypeLimit && available(b, direction, p.Price) > 0 {
+		if p.RejectPostOnly {
+			return nil, Error(ErrCodePostOnlyReject, "post_only_reject")
+		}
+		p.Price = postOnlyPrice(b, direction, p.Price)
+	}
+
+	ts := now()
+	order := &models.Order{
+		OrderID:             id,
+		InstrumentName:      p.InstrumentName,
+		Direction:           direction,
+		Amount:              amount,
+		Price:               models.Price(p.Price),
+		OrderType:           p.Type,
+		OrderState:          models.OrderStateOpen,
+		TimeInForce:         p.TimeInForce,
+		Label:               p.Label,
+		PostOnly:            p.PostOnly,
+		ReduceOnly:          p.ReduceOnly,
+		StopPrice:           p.StopPrice,
+		Advanced:            p.Advanced,
+		API:                 true,
+		MaxShow:             amount,
+		CreationTimestamp:   ts,
+		LastUpdateTimestamp: ts,
+	}
+	if p.MaxShow != nil {
+		order.MaxShow = *p.MaxShow
+	}
+	e.orders[order.OrderID] = order
+
+	if p.Type == models.OrderTypeStopLimit || p.Type == models.OrderTypeStopMarket {
+		order.OrderState = models.OrderStateUntriggered
+		trigger := p.Trigger
+		if trigger == "" {
+			trigger = models.TriggerTypeLastPrice
+		}
+		e.triggers[order.OrderID] = trigger
+		b.stops = append(b.stops, order)
+		e.changed(order, c)
+		e.triggerStops(b, c)
+		return order, nil
+	}
+	e.execute(b, order, c)
+	e.triggerStops(b, c)
+	return order, nil
+}
+
+// edit changes price and amount of an open order, it loses its time priority
+func (e *engine) edit(p *models.EditParams, c *changes) (*models.Order, error) {
+	order, ok := e.orders[p.OrderID]
+	if !ok || !isOpen(order) {
+		return nil, Error(ErrCodeNotOpenOrder, "not_open_order")
+	}
+	if p.Amount < order.FilledAmount || p.Amount <= 0 {
+		return nil, Error(ErrCodeInvalidParams, "Invalid params: amount")
+	}
+	b := e.book(order.InstrumentName)
+	if p.PostOnly && order.OrderType == models.OrderTypeLimit && available(b, order.Direction, p.Price) > 0 {
+		if p.RejectPostOnly {
+			return nil, Error(ErrCodePostOnlyReject, "post_only_reject")
+		}
+		p.Price = postOnlyPrice(b, order.Direction, p.Price)
+	}
+	remove(b, order)
+	order.Amount = p.Amount
+	if !isMarket(order) {
+		order.Price = models.Price(p.Price)
+	}
+	order.PostOnly = p.PostOnly
+	if p.StopPrice != 0 {
+		order.StopPrice = p.StopPrice
+	}
+	order.LastUpdateTimestamp = now()
+	if order.OrderState == models.OrderStateUntriggered {
+		b.stops = append(b.stops, order)
+		e.changed(order, c)
+		e.triggerStops(b, c)
+		return order, nil
+	}
+	if order.FilledAmount >= order.Amount {
+		order.OrderState = models.OrderStateFilled
+		e.changed(order, c)
+		return order, nil
+	}
+	e.execute(b, order, c)
+	e.triggerStops(b, c)
+	return order, nil
+}
+
+func (e *engine) cancel(order *models.Order, c *changes) {
+	remove(e.book(order.InstrumentName), order)
+	order.OrderState = models.OrderStateCancelled
+	order.LastUpdateTimestamp = now()
+	e.changed(order, c)
+}
+
+// orderBook aggregates the resting orders of b into price levels
+func (b *matchBook) orderBook(depth int) models.GetOrderBookResponse {
+	levels := func(entries []*entry) [][]float64 {
+		result := [][]float64{}
+		for _, v := range entries {
+			if n := len(result); n > 0 && result[n-1][0] == v.price {
+				result[n-1][1] += v.amount
+				continue
+			}
+			if depth > 0 && len(result) == depth {
+				break
+			}
+			result = append(result, []float64{v.price, v.amount})
+		}
+		return result
+	}
+	book := models.GetOrderBookResponse{
+		Timestamp:      now(),
+		State:          "open",
+		InstrumentName: b.name,
+		ChangeID:       int(b.changeID),
+		Bids:           levels(b.bids),
+		Asks:           levels(b.asks),
+		LastPrice:      b.last,
+		MarkPrice:      b.mark,
+		IndexPrice:     b.index,
+	}
+	if len(book.Bids) > 0 {
+		book.BestBidPrice, book.BestBidAmount = book.Bids[0][0], book.Bids[0][1]
+	}
+	if len(book.Asks) > 0 {
+		book.BestAskPrice, book.BestAskAmount = book.Asks[0][0], book.Asks[0][1]
+	}
+	return book
+}
+
+// currencyOf returns the base currency of an instrument name, e.g. BTC for BTC-PERPETUAL
+func currencyOf(instrumentName string) string {
+	if i := strings.IndexAny(instrumentName, "-_"); i > 0 {
+		return instrumentName[:i]
+	}
+	return instrumentName
+}
+
+// kindOf returns the kind of an instrument name: option, spot or future
+func kindOf(instrumentName string) models.Kind {
+	switch {
+	case strings.HasSuffix(instrumentName, "-C") || strings.HasSuffix(instrumentName, "-P"):
+		return models.KindOption
+	case strings.Contains(instrumentName, "_") && !strings.Contains(instrumentName, "-"):
+		return models.KindSpot
+	default:
+		return models.KindFuture
+	}
+}
+
+// channelCovers reports whether a subscribed channel such as user.orders.BTC-PERPETUAL.raw
+// or user.orders.future.BTC.100ms receives notifications of prefix for instrumentName
+func channelCovers(channel string, prefix string, instrumentName string) bool {
+	if !strings.HasPrefix(channel, prefix+".") {
+		return false
+	}
+	parts := strings.Split(strings.TrimPrefix(channel, prefix+"."), ".")
+	switch len(parts) {
+	case 2:
+		return parts[0] == instrumentName
+	case 3:
+		kind, currency := parts[0], parts[1]
+		return (kind == "any" || models.Kind(kind) == kindOf(instrumentName)) &&
+			(currency == "any" || strings.EqualFold(currency, currencyOf(instrumentName)))
+	}
+	return false
+}
+
+// publishChanges queues the notifications for c to the subscribed connections
+func (s *Server) publishChanges(c *changes) {
+	byInstrument := func(name string) (orders []models.Order, trades []models.UserTrade, public []models.Trade) {
+		for _, v := range c.orders {
+			if v.InstrumentName == name {
+				orders = append(orders, v)
+			}
+		}
+		for _, v := range c.trades {
+			if v.InstrumentName == name {
+				trades = append(trades, v)
+			}
+		}
+		for _, v := range c.public {
+			if v.InstrumentName == name {
+				public = append(public, v)
+			}
+		}
+		return
+	}
+	var names []string
+	seen := make(map[string]struct{})
+	for _, v := range c.orders {
+		if _, ok := seen[v.InstrumentName]; !ok {
+			seen[v.InstrumentName] = struct{}{}
+			names = append(names, v.InstrumentName)
+		}
+	}
+	for _, v := range c.public {
+		if _, ok := seen[v.InstrumentName]; !ok {
+			seen[v.InstrumentName] = struct{}{}
+			names = append(names, v.InstrumentName)
+		}
+	}
+	for _, name := range names {
+		orders, trades, public := byInstrument(name)
+		var positions []models.Position
+		if _, ok := c.positions[name]; ok {
+			s.engine.mu.Lock()
+			positions = append(positions, *s.engine.positions[name])
+			s.engine.mu.Unlock()
+		}
+		if len(orders) > 0 {
+			s.publish("user.orders", name, orders)
+		}
+		if len(trades) > 0 {
+			s.publish("user.trades", name, trades)
+		}
+		if len(orders) > 0 || len(trades) > 0 {
+			s.publish("user.changes", name, models.UserChangesNotification{
+				Trades:    nonNilTrades(trades),
+				Positions: nonNilPositions(positions),
+				Orders:    orders,
+			})
+		}
+		if len(public) > 0 {
+			s.publish("trades", name, public)
+		}
+	}
+}
+
+func nonNilTrades(trades []models.UserTrade) []models.UserTrade {
+	if trades == nil {
+		return []models.UserTrade{}
+	}
+	return trades
+}
+
+func nonNilPositions(positions []models.Position) []models.Position {
+	if positions == nil {
+		return []models.Position{}
+	}
+	return positions
+}
+
+// notification is a queued subscription notification
+type notification struct {
+	prefix         string
+	instrumentName string
+	data           json.RawMessage
+}
+
+// publish queues data for every channel of prefix covering instrumentName,
+// notifications are delivered in order after the response of the current call
+func (s *Server) publish(prefix string, instrumentName string, data interface{}) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	select {
+	case s.notifications <- notification{prefix: prefix, instrumentName: instrumentName, data: b}:
+	case <-s.done:
+	}
+}
+
+func (s *Server) deliver() {
+	for {
+		select {
+		case n := <-s.notifications:
+			for _, conn := range s.Conns() {
+				for _, channel := range conn.Subscriptions() {
+					if channelCovers(channel, n.prefix, n.instrumentName) {
+						conn.Notify(channel, n.data)
+					}
+				}
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
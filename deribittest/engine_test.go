@@ -0,0 +1,177 @@
+package deribittest_test
+
+import (
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newClient(server *deribittest.Server) *deribit.Client {
+	return deribit.New(&deribit.Configuration{
+		Addr:      server.URL,
+		ApiKey:    "key",
+		SecretKey: "secret",
+	})
+}
+
+func TestEngine_Match(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SeedBook("BTC-PERPETUAL", [][]float64{{5999, 10}}, [][]float64{{6001, 10}, {6002, 20}})
+	client := newClient(server)
+
+	buy, err := client.Buy(&models.BuyParams{
+		InstrumentName: "BTC-PERPETUAL",
+		Amount:         25,
+		Price:          6002,
+		Type:           models.OrderTypeLimit,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, models.OrderStateFilled, buy.Order.OrderState)
+	assert.Equal(t, 25.0, buy.Order.FilledAmount)
+	assert.InDelta(t, (6001*10+6002*15)/25.0, buy.Order.AveragePrice, 1e-9)
+	assert.Len(t, buy.Trades, 2)
+
+	book, err := client.GetOrderBook(&models.GetOrderBookParams{InstrumentName: "BTC-PERPETUAL"})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]float64{{6002, 5}}, book.Asks)
+	assert.Equal(t, 6002.0, book.LastPrice)
+
+	position, err := client.GetPosition(&models.GetPositionParams{InstrumentName: "BTC-PERPETUAL"})
+	assert.Nil(t, err)
+	assert.Equal(t, 25.0, position.Size)
+	assert.Equal(t, models.DirectionBuy, position.Direction)
+}
+
+func TestEngine_PriceTimePriority(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	client := newClient(server)
+
+	first, _ := client.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6001})
+	second, _ := client.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6001})
+	better, _ := client.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000})
+
+	_, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 15, Price: 6001})
+	assert.Nil(t, err)
+
+	order, _ := server.Order(better.Order.OrderID)
+	assert.Equal(t, models.OrderStateFilled, order.OrderState)
+	order, _ = server.Order(first.Order.OrderID)
+	assert.Equal(t, 5.0, order.FilledAmount)
+	order, _ = server.Order(second.Order.OrderID)
+	assert.Equal(t, 0.0, order.FilledAmount)
+}
+
+func TestEngine_TimeInForce(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SeedBook("BTC-PERPETUAL", nil, [][]float64{{6001, 10}})
+	client := newClient(server)
+
+	_, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6001, PostOnly: true, RejectPostOnly: true})
+	assert.NotNil(t, err)
+	postOnly, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6001, PostOnly: true})
+	assert.Nil(t, err)
+	assert.Equal(t, models.OrderStateOpen, postOnly.Order.OrderState)
+	assert.Equal(t, models.Price(6000.5), postOnly.Order.Price, "post only orders are repriced just below the spread")
+	_, err = client.Cancel(&models.CancelParams{OrderID: postOnly.Order.OrderID})
+	assert.Nil(t, err)
+
+	fok, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 20, Price: 6001, TimeInForce: models.TimeInForceFillOrKill})
+	assert.Nil(t, err)
+	assert.Equal(t, models.OrderStateCancelled, fok.Order.OrderState)
+	assert.Equal(t, 0.0, fok.Order.FilledAmount)
+
+	ioc, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 20, Price: 6001, TimeInForce: models.TimeInForceImmediateOrCancel})
+	assert.Nil(t, err)
+	assert.Equal(t, models.OrderStateCancelled, ioc.Order.OrderState)
+	assert.Equal(t, 10.0, ioc.Order.FilledAmount)
+
+	_, err = client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, ReduceOnly: true, Type: models.OrderTypeMarket})
+	assert.NotNil(t, err)
+	sell, err := client.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 50, Price: 7000, ReduceOnly: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, sell.Order.Amount)
+}
+
+func TestEngine_Stop(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SeedBook("BTC-PERPETUAL", nil, [][]float64{{6101, 100}})
+	client := newClient(server)
+
+	stop, err := client.Buy(&models.BuyParams{
+		InstrumentName: "BTC-PERPETUAL",
+		Amount:         10,
+		Type:           models.OrderTypeStopMarket,
+		StopPrice:      6100,
+		Trigger:        models.TriggerTypeLastPrice,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, models.OrderStateUntriggered, stop.Order.OrderState)
+
+	server.Trade("BTC-PERPETUAL", 6099, 1)
+	order, _ := server.Order(stop.Order.OrderID)
+	assert.Equal(t, models.OrderStateUntriggered, order.OrderState)
+
+	server.Trade("BTC-PERPETUAL", 6100, 1)
+	order, _ = server.Order(stop.Order.OrderID)
+	assert.True(t, order.Triggered)
+	assert.Equal(t, models.OrderStateFilled, order.OrderState)
+	assert.Equal(t, 6101.0, order.AveragePrice)
+}
+
+func TestEngine_Notifications(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	client := newClient(server)
+
+	orders := make(chan *models.UserOrderNotification, 8)
+	client.On("user.orders.future.BTC.raw", func(e *models.UserOrderNotification) {
+		orders <- e
+	})
+	trades := make(chan *models.UserTradesNotification, 8)
+	client.On("user.trades.BTC-PERPETUAL.raw", func(e *models.UserTradesNotification) {
+		trades <- e
+	})
+	changes := make(chan *models.UserChangesNotification, 8)
+	client.On("user.changes.any.any.raw", func(e *models.UserChangesNotification) {
+		changes <- e
+	})
+	client.Subscribe([]string{"user.orders.future.BTC.raw", "user.trades.BTC-PERPETUAL.raw", "user.changes.any.any.raw"})
+
+	buy, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000})
+	assert.Nil(t, err)
+	select {
+	case e := <-orders:
+		assert.Equal(t, buy.Order.OrderID, (*e)[0].OrderID)
+		assert.Equal(t, models.OrderStateOpen, (*e)[0].OrderState)
+	case <-time.After(time.Second):
+		t.Fatal("no order notification")
+	}
+	<-changes
+
+	server.ApplyBookChange(models.OrderBookRawNotification{
+		InstrumentName: "BTC-PERPETUAL",
+		Asks:           []models.OrderBookNotificationItem{{Action: "new", Price: 5999, Amount: 4}},
+	})
+	select {
+	case e := <-trades:
+		assert.Equal(t, 4.0, (*e)[0].Amount)
+		assert.Equal(t, 6000.0, (*e)[0].Price)
+	case <-time.After(time.Second):
+		t.Fatal("no trade notification")
+	}
+	select {
+	case e := <-changes:
+		assert.Len(t, e.Trades, 1)
+		assert.Equal(t, 4.0, e.Positions[0].Size)
+		assert.Equal(t, 4.0, e.Orders[0].FilledAmount)
+	case <-time.After(time.Second):
+		t.Fatal("no changes notification")
+	}
+}
@@ -0,0 +1,493 @@
+package deribittest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/frankrap/deribit-api/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version is the API version reported by public/test and public/hello
+const Version = "1.2.26"
+
+// store holds the tokens issued by a Server
+type store struct {
+	mu     sync.Mutex
+	tokens map[string]struct{}
+	nextID int64
+}
+
+func newStore() *store {
+	return &store{
+		tokens: make(map[string]struct{}),
+		nextID: 1000,
+	}
+}
+
+func (s *store) newToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	token := "token" + strconv.FormatInt(s.nextID, 10)
+	s.tokens[token] = struct{}{}
+	return token
+}
+
+func (s *store) validToken(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.tokens[token]
+	return ok
+}
+
+// now returns the current time in milliseconds
+func now() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+func decode(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return Error(ErrCodeInvalidParams, fmt.Sprintf("Invalid params: %v", err))
+	}
+	return nil
+}
+
+func okHandler(conn *Conn, params json.RawMessage) (interface{}, error) {
+	return "ok", nil
+}
+
+func (s *Server) registerDefaults() {
+	s.handlers["public/auth"] = s.auth
+	s.handlers["public/subscribe"] = s.subscribe(false)
+	s.handlers["private/subscribe"] = s.subscribe(true)
+	s.handlers["public/unsubscribe"] = s.unsubscribe
+	s.handlers["private/unsubscribe"] = s.unsubscribe
+	s.handlers["public/set_heartbeat"] = okHandler
+	s.handlers["public/disable_heartbeat"] = okHandler
+	s.handlers["private/enable_cancel_on_disconnect"] = okHandler
+	s.handlers["private/disable_cancel_on_disconnect"] = okHandler
+	s.handlers["public/test"] = func(conn *Conn, params json.RawMessage) (interface{}, error) {
+		return models.TestResponse{Version: Version}, nil
+	}
+	s.handlers["public/hello"] = func(conn *Conn, params json.RawMessage) (interface{}, error) {
+		return models.HelloResponse{Version: Version}, nil
+	}
+	s.handlers["public/get_time"] = func(conn *Conn, params json.RawMessage) (interface{}, error) {
+		return now(), nil
+	}
+	s.handlers["public/get_order_book"] = s.getOrderBook
+	s.handlers["public/ticker"] = s.ticker
+	s.handlers["private/buy"] = s.placeOrder(models.DirectionBuy)
+	s.handlers["private/sell"] = s.placeOrder(models.DirectionSell)
+	s.handlers["private/edit"] = s.edit
+	s.handlers["private/cancel"] = s.cancel
+	s.handlers["private/cancel_all"] = s.cancelAll
+	s.handlers["private/cancel_all_by_instrument"] = s.cancelAll
+	s.handlers["private/close_position"] = s.closePosition
+	s.handlers["private/get_order_state"] = s.getOrderState
+	s.handlers["private/get_open_orders_by_currency"] = s.getOpenOrdersByCurrency
+	s.handlers["private/get_open_orders_by_instrument"] = s.getOpenOrdersByInstrument
+	s.handlers["private/get_order_history_by_instrument"] = s.getOrderHistoryByInstrument
+	s.handlers["private/get_position"] = s.getPosition
+	s.handlers["private/get_positions"] = s.getPositions
+	s.handlers["private/get_user_trades_by_instrument"] = s.getUserTradesByInstrument
+}
+
+func (s *Server) auth(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p struct {
+		GrantType    string `json:"grant_type"`
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	switch p.GrantType {
+	case "client_credentials":
+		if !s.validCredentials(p.ClientID, p.ClientSecret) {
+			return nil, Error(ErrCodeUnauthorized, "invalid_credentials")
+		}
+	case "refresh_token":
+		if !s.store.validToken(p.RefreshToken) {
+			return nil, Error(ErrCodeUnauthorized, "invalid_token")
+		}
+	default:
+		return nil, Error(ErrCodeInvalidParams, "Invalid params")
+	}
+	conn.mu.Lock()
+	conn.authenticated = true
+	conn.mu.Unlock()
+	return models.AuthResponse{
+		AccessToken:  s.store.newToken(),
+		ExpiresIn:    31536000,
+		RefreshToken: s.store.newToken(),
+		Scope:        "connection mainaccount",
+		TokenType:    "bearer",
+	}, nil
+}
+
+func (s *Server) subscribe(private bool) HandlerFunc {
+	return func(conn *Conn, params json.RawMessage) (interface{}, error) {
+		var p models.SubscribeParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+
+		result := models.SubscribeResponse{}
+		for _, channel := range p.Channels {
+			if !private && strings.HasPrefix(channel, "user.") {
+				continue
+			}
+			conn.subscriptions[channel] = struct{}{}
+			result = append(result, channel)
+		}
+		return result, nil
+	}
+}
+
+func (s *Server) unsubscribe(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.UnsubscribeParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	result := models.UnsubscribeResponse{}
+	for _, channel := range p.Channels {
+		delete(conn.subscriptions, channel)
+		result = append(result, channel)
+	}
+	return result, nil
+}
+
+// SetOrderBook seeds the book of an instrument with the levels of book and sets its prices
+func (s *Server) SetOrderBook(book models.GetOrderBookResponse) {
+	s.SeedBook(book.InstrumentName, book.Bids, book.Asks)
+	s.SetPrices(book.InstrumentName, book.MarkPrice, book.IndexPrice)
+}
+
+func (s *Server) getOrderBook(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.GetOrderBookParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	s.engine.mu.Lock()
+	defer s.engine.mu.Unlock()
+
+	return s.engine.book(p.InstrumentName).orderBook(p.Depth), nil
+}
+
+func (s *Server) ticker(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.TickerParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	s.engine.mu.Lock()
+	book := s.engine.book(p.InstrumentName).orderBook(1)
+	s.engine.mu.Unlock()
+
+	return models.TickerResponse{
+		BestAskAmount:  book.BestAskAmount,
+		BestAskPrice:   book.BestAskPrice,
+		BestBidAmount:  book.BestBidAmount,
+		BestBidPrice:   book.BestBidPrice,
+		IndexPrice:     book.IndexPrice,
+		InstrumentName: book.InstrumentName,
+		LastPrice:      book.LastPrice,
+		MarkPrice:      book.MarkPrice,
+		State:          book.State,
+		Timestamp:      book.Timestamp,
+	}, nil
+}
+
+func (s *Server) placeOrder(direction models.Direction) HandlerFunc {
+	return func(conn *Conn, params json.RawMessage) (interface{}, error) {
+		var p models.BuyParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		id := strconv.FormatInt(s.nextID(), 10)
+		c := &changes{}
+		s.engine.mu.Lock()
+		order, err := s.engine.place(direction, &p, id, c)
+		var result models.BuyResponse
+		if err == nil {
+			result = models.BuyResponse{Trades: tradesOf(c, order.OrderID), Order: *order}
+		}
+		s.engine.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		s.publishChanges(c)
+		return result, nil
+	}
+}
+
+// tradesOf returns the trades of order in c
+func tradesOf(c *changes, orderID string) []models.Trade {
+	trades := []models.Trade{}
+	for _, v := range c.trades {
+		if v.OrderID == orderID {
+			trades = append(trades, models.Trade{
+				TradeSeq:       v.TradeSeq,
+				TradeID:        v.TradeID,
+				Timestamp:      v.Timestamp,
+				Price:          v.Price,
+				InstrumentName: v.InstrumentName,
+				IndexPrice:     v.IndexPrice,
+				Direction:      v.Direction,
+				Amount:         v.Amount,
+			})
+		}
+	}
+	return trades
+}
+
+func (s *Server) edit(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.EditParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	c := &changes{}
+	s.engine.mu.Lock()
+	order, err := s.engine.edit(&p, c)
+	var result models.EditResponse
+	if err == nil {
+		result = models.EditResponse{Trades: tradesOf(c, order.OrderID), Order: *order}
+	}
+	s.engine.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	s.publishChanges(c)
+	return result, nil
+}
+
+func (s *Server) cancel(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.CancelParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	c := &changes{}
+	s.engine.mu.Lock()
+	order, ok := s.engine.orders[p.OrderID]
+	if !ok || !isOpen(order) {
+		s.engine.mu.Unlock()
+		return nil, Error(ErrCodeNotOpenOrder, "not_open_order")
+	}
+	s.engine.cancel(order, c)
+	result := *order
+	s.engine.mu.Unlock()
+
+	s.publishChanges(c)
+	return result, nil
+}
+
+func (s *Server) cancelAll(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.CancelAllByInstrumentParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	c := &changes{}
+	s.engine.mu.Lock()
+	n := 0
+	for _, order := range s.engine.orders {
+		if p.InstrumentName != "" && order.InstrumentName != p.InstrumentName {
+			continue
+		}
+		if isOpen(order) {
+			s.engine.cancel(order, c)
+			n++
+		}
+	}
+	s.engine.mu.Unlock()
+
+	s.publishChanges(c)
+	return n, nil
+}
+
+// closePosition places a reduce only order for the whole position
+func (s *Server) closePosition(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.ClosePositionParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	id := strconv.FormatInt(s.nextID(), 10)
+	c := &changes{}
+	s.engine.mu.Lock()
+	direction, size := models.DirectionSell, 0.0
+	if position, ok := s.engine.positions[p.InstrumentName]; ok {
+		size = position.Size
+	}
+	if size < 0 {
+		direction, size = models.DirectionBuy, -size
+	}
+	order, err := s.engine.place(direction, &models.BuyParams{
+		InstrumentName: p.InstrumentName,
+		Amount:         size,
+		Type:           p.Type,
+		Price:          p.Price,
+		ReduceOnly:     true,
+	}, id, c)
+	var result models.ClosePositionResponse
+	if err == nil {
+		result = models.ClosePositionResponse{Trades: tradesOf(c, order.OrderID), Order: *order}
+	}
+	s.engine.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	s.publishChanges(c)
+	return result, nil
+}
+
+func (s *Server) getOrderState(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.GetOrderStateParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	order, ok := s.Order(p.OrderID)
+	if !ok {
+		return nil, Error(ErrCodeNotFound, "order_not_found")
+	}
+	return order, nil
+}
+
+func (s *Server) getOpenOrdersByInstrument(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.GetOpenOrdersByInstrumentParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	result := []models.Order{}
+	for _, order := range s.Orders() {
+		if order.InstrumentName == p.InstrumentName && isOpen(&order) {
+			result = append(result, order)
+		}
+	}
+	return result, nil
+}
+
+func (s *Server) getOpenOrdersByCurrency(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.GetOpenOrdersByCurrencyParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	result := []models.Order{}
+	for _, order := range s.Orders() {
+		if !strings.EqualFold(currencyOf(order.InstrumentName), p.Currency) || !isOpen(&order) {
+			continue
+		}
+		if p.Kind != "" && p.Kind != "any" && p.Kind != kindOf(order.InstrumentName) {
+			continue
+		}
+		result = append(result, order)
+	}
+	return result, nil
+}
+
+func (s *Server) getOrderHistoryByInstrument(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.GetOrderHistoryByInstrumentParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	result := []models.Order{}
+	orders := s.Orders()
+	for i := len(orders) - 1; i >= 0; i-- {
+		order := orders[i]
+		if order.InstrumentName != p.InstrumentName || isOpen(&order) {
+			continue
+		}
+		if order.FilledAmount == 0 && !p.IncludeUnfilled {
+			continue
+		}
+		result = append(result, order)
+	}
+	if p.Offset > 0 {
+		if p.Offset >= len(result) {
+			result = []models.Order{}
+		} else {
+			result = result[p.Offset:]
+		}
+	}
+	count := p.Count
+	if count <= 0 {
+		count = 20
+	}
+	if len(result) > count {
+		result = result[:count]
+	}
+	return result, nil
+}
+
+func (s *Server) getPosition(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.GetPositionParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	for _, position := range s.Positions() {
+		if position.InstrumentName == p.InstrumentName {
+			return position, nil
+		}
+	}
+	return models.Position{
+		InstrumentName: p.InstrumentName,
+		Kind:           kindOf(p.InstrumentName),
+		Direction:      "zero",
+	}, nil
+}
+
+func (s *Server) getPositions(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.GetPositionsParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	result := []models.Position{}
+	for _, position := range s.Positions() {
+		if !strings.EqualFold(currencyOf(position.InstrumentName), p.Currency) {
+			continue
+		}
+		if p.Kind != "" && p.Kind != "any" && p.Kind != position.Kind {
+			continue
+		}
+		result = append(result, position)
+	}
+	return result, nil
+}
+
+func (s *Server) getUserTradesByInstrument(conn *Conn, params json.RawMessage) (interface{}, error) {
+	var p models.GetUserTradesByInstrumentParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	count := p.Count
+	if count <= 0 {
+		count = 10
+	}
+	result := models.GetUserTradesResponse{Trades: []models.UserTrade{}}
+	for _, trade := range s.UserTrades() {
+		if trade.InstrumentName != p.InstrumentName {
+			continue
+		}
+		if (p.StartSeq > 0 && trade.TradeSeq < p.StartSeq) || (p.EndSeq > 0 && trade.TradeSeq > p.EndSeq) {
+			continue
+		}
+		if len(result.Trades) == count {
+			result.HasMore = true
+			break
+		}
+		result.Trades = append(result.Trades, trade)
+	}
+	return result, nil
+}
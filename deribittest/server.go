@@ -0,0 +1,436 @@
+// Package deribittest provides an in-process Deribit server for testing.
+//
+// The Server speaks JSON-RPC over WebSocket (and HTTP) like the real exchange,
+// implements the common public and private methods with an in-memory state,
+// lets tests script responses per method and inject subscription notifications.
+//
+// Orders are matched by a price-time priority engine per instrument against
+// the user's other orders and liquidity seeded with SeedBook or replayed with
+// ApplyBookChange. Fills are reported on the user.orders, user.trades,
+// user.changes and trades channels, so strategies can be paper traded.
+package deribittest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/sourcegraph/jsonrpc2"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// WebSocketPath is the path of the WebSocket API
+	WebSocketPath = "/ws/api/v2/"
+	// HTTPPath is the path prefix of the HTTP API
+	HTTPPath = "/api/v2/"
+)
+
+// Error codes returned by the server, as used by Deribit
+const (
+	ErrCodeUnauthorized   = 13009
+	ErrCodeNotFound       = 13020
+	ErrCodeNotOpenOrder   = 11044
+	ErrCodeInvalidParams  = -32602
+	ErrCodeMethodNotFound = -32601
+)
+
+// HandlerFunc answers a call, params is the raw params object of the request
+type HandlerFunc func(conn *Conn, params json.RawMessage) (result interface{}, err error)
+
+// Call is a call received by the server
+type Call struct {
+	Method string
+	Params json.RawMessage
+}
+
+// Server is an in-process Deribit server
+type Server struct {
+	// URL is the WebSocket address, e.g. to use as deribit.Configuration.Addr
+	URL string
+	// HTTPURL is the HTTP API address
+	HTTPURL string
+
+	httpServer *httptest.Server
+
+	mu       sync.Mutex
+	apiKey   string
+	secret   string
+	handlers map[string]HandlerFunc
+	conns    map[*Conn]struct{}
+	calls    []Call
+	headers  []http.Header
+	seq      int64
+	async    bool
+
+	store         *store
+	engine        *engine
+	notifications chan notification
+	done          chan struct{}
+}
+
+// NewServer starts a Server
+func NewServer() *Server {
+	s := &Server{
+		handlers:      make(map[string]HandlerFunc),
+		conns:         make(map[*Conn]struct{}),
+		store:         newStore(),
+		engine:        newEngine(),
+		notifications: make(chan notification, 1024),
+		done:          make(chan struct{}),
+	}
+	s.registerDefaults()
+	go s.deliver()
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = "ws" + strings.TrimPrefix(s.httpServer.URL, "http") + WebSocketPath
+	s.HTTPURL = s.httpServer.URL + HTTPPath
+	return s
+}
+
+// Close disconnects all clients and shuts the server down
+func (s *Server) Close() {
+	close(s.done)
+	s.DisconnectAll()
+	s.httpServer.Close()
+}
+
+// SetCredentials sets the only API key and secret accepted by public/auth.
+// By default any credentials are accepted.
+func (s *Server) SetCredentials(apiKey string, secretKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apiKey = apiKey
+	s.secret = secretKey
+}
+
+// Handle sets the handler of method, replacing the default one
+func (s *Server) Handle(method string, handler HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[method] = handler
+}
+
+// Handler returns the handler of method, e.g. to wrap a default one passed to Handle
+func (s *Server) Handler(method string) HandlerFunc {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.handlers[method]
+}
+
+// SetAsync makes connections opened afterwards answer their calls concurrently instead
+// of one at a time, e.g. to exercise concurrent callers or calls overtaking each other
+func (s *Server) SetAsync(async bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.async = async
+}
+
+// Respond makes method always answer result
+func (s *Server) Respond(method string, result interface{}) {
+	s.Handle(method, func(conn *Conn, params json.RawMessage) (interface{}, error) {
+		return result, nil
+	})
+}
+
+// RespondError makes method always fail with the given error
+func (s *Server) RespondError(method string, code int64, message string) {
+	s.Handle(method, func(conn *Conn, params json.RawMessage) (interface{}, error) {
+		return nil, Error(code, message)
+	})
+}
+
+// Error returns a JSON-RPC error with code and message
+func Error(code int64, message string) error {
+	return &jsonrpc2.Error{Code: code, Message: message}
+}
+
+// Calls returns the calls received so far
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Call(nil), s.calls...)
+}
+
+// CallCount returns the number of calls of method received so far
+func (s *Server) CallCount(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, call := range s.calls {
+		if call.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// Headers returns the headers of the WebSocket handshakes received so far
+func (s *Server) Headers() []http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]http.Header(nil), s.headers...)
+}
+
+// Conns returns the open WebSocket connections
+func (s *Server) Conns() []*Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var conns []*Conn
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// Subscribed reports whether any connection is subscribed to channel
+func (s *Server) Subscribed(channel string) bool {
+	for _, conn := range s.Conns() {
+		if conn.Subscribed(channel) {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitSubscribed waits until a connection subscribes to channel, and reports whether one did
+func (s *Server) WaitSubscribed(channel string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if s.Subscribed(channel) {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+// Notify sends a subscription notification with data to every connection subscribed to channel
+func (s *Server) Notify(channel string, data interface{}) {
+	for _, conn := range s.Conns() {
+		if conn.Subscribed(channel) {
+			conn.Notify(channel, data)
+		}
+	}
+}
+
+// NotifyRaw sends a subscription notification with raw JSON data
+func (s *Server) NotifyRaw(channel string, data string) {
+	s.Notify(channel, json.RawMessage(data))
+}
+
+// DisconnectAll closes every WebSocket connection, e.g. to exercise reconnects
+func (s *Server) DisconnectAll() {
+	for _, conn := range s.Conns() {
+		conn.Close()
+	}
+}
+
+// nextID returns a new sequence number used for ids and timestamps
+func (s *Server) nextID() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	return s.seq
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, HTTPPath) {
+		s.serveRPC(w, r)
+		return
+	}
+	c, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	c.SetReadLimit(32768 * 64)
+
+	conn := &Conn{
+		server:        s,
+		ws:            c,
+		subscriptions: make(map[string]struct{}),
+	}
+	var handler jsonrpc2.Handler = jsonrpc2.HandlerWithError(
+		func(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+			var params json.RawMessage
+			if req.Params != nil {
+				params = *req.Params
+			}
+			return s.dispatch(conn, req.Method, params)
+		})
+	s.mu.Lock()
+	if s.async {
+		handler = jsonrpc2.AsyncHandler(handler)
+	}
+	s.mu.Unlock()
+	conn.rpc = jsonrpc2.NewConn(context.Background(), objectStream{conn: c}, handler)
+	s.mu.Lock()
+	s.conns[conn] = struct{}{}
+	s.headers = append(s.headers, r.Header.Clone())
+	s.mu.Unlock()
+
+	<-conn.rpc.DisconnectNotify()
+
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+}
+
+// serveRPC serves the HTTP API, every request is a JSON-RPC call authorized by its header
+func (s *Server) serveRPC(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	var req struct {
+		ID     uint64          `json:"id"`
+		Params json.RawMessage `json:"params"`
+	}
+	json.Unmarshal(body, &req)
+	conn := &Conn{server: s, subscriptions: make(map[string]struct{})}
+	conn.authenticated = s.authorizedHTTP(r.Header.Get("Authorization"))
+
+	method := strings.TrimPrefix(r.URL.Path, HTTPPath)
+	result, err := s.dispatch(conn, method, req.Params)
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	if err != nil {
+		e, ok := err.(*jsonrpc2.Error)
+		if !ok {
+			e = &jsonrpc2.Error{Message: err.Error()}
+		}
+		resp["error"] = e
+		w.WriteHeader(http.StatusBadRequest)
+	} else {
+		resp["result"] = result
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) authorizedHTTP(authorization string) bool {
+	if strings.HasPrefix(authorization, "bearer ") {
+		return s.store.validToken(strings.TrimPrefix(authorization, "bearer "))
+	}
+	if strings.HasPrefix(authorization, "Basic ") {
+		data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(authorization, "Basic "))
+		if err != nil {
+			return false
+		}
+		l := strings.SplitN(string(data), ":", 2)
+		return len(l) == 2 && s.validCredentials(l[0], l[1])
+	}
+	return false
+}
+
+func (s *Server) validCredentials(apiKey string, secretKey string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.apiKey == "" || (apiKey == s.apiKey && secretKey == s.secret)
+}
+
+func (s *Server) dispatch(conn *Conn, method string, params json.RawMessage) (interface{}, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{Method: method, Params: params})
+	handler, ok := s.handlers[method]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, Error(ErrCodeMethodNotFound, "Method not found")
+	}
+	if strings.HasPrefix(method, "private/") && !conn.Authenticated() {
+		return nil, Error(ErrCodeUnauthorized, "unauthorized")
+	}
+	return handler(conn, params)
+}
+
+// Conn is a client connection to the Server
+type Conn struct {
+	server *Server
+	ws     *websocket.Conn
+	rpc    *jsonrpc2.Conn
+
+	mu            sync.Mutex
+	authenticated bool
+	subscriptions map[string]struct{}
+}
+
+// Authenticated reports whether the connection called public/auth successfully
+func (c *Conn) Authenticated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.authenticated
+}
+
+// Subscribed reports whether the connection is subscribed to channel
+func (c *Conn) Subscribed(channel string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.subscriptions[channel]
+	return ok
+}
+
+// Subscriptions returns the channels the connection is subscribed to
+func (c *Conn) Subscriptions() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var channels []string
+	for channel := range c.subscriptions {
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// Notify sends a subscription notification on this connection
+func (c *Conn) Notify(channel string, data interface{}) error {
+	if c.rpc == nil {
+		return nil
+	}
+	return c.rpc.Notify(context.Background(), "subscription", map[string]interface{}{
+		"channel": channel,
+		"data":    data,
+	})
+}
+
+// Close closes the connection
+func (c *Conn) Close() error {
+	if c.rpc == nil {
+		return nil
+	}
+	return c.rpc.Close()
+}
+
+// objectStream is a jsonrpc2.ObjectStream over a WebSocket
+type objectStream struct {
+	conn *websocket.Conn
+}
+
+func (t objectStream) WriteObject(obj interface{}) error {
+	return wsjson.Write(context.Background(), t.conn, obj)
+}
+
+func (t objectStream) ReadObject(v interface{}) error {
+	err := wsjson.Read(context.Background(), t.conn, v)
+	if websocket.CloseStatus(err) != -1 {
+		err = io.EOF
+	}
+	return err
+}
+
+func (t objectStream) Close() error {
+	return t.conn.Close(websocket.StatusNormalClosure, "")
+}
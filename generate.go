@@ -0,0 +1,5 @@
+package deribit
+
+// The api_*.go files and the models of their params and results are generated
+// from cmd/gen/deribit_api.json
+//go:generate go run ./cmd/gen
@@ -0,0 +1,67 @@
+// Package config loads the deribit.Configuration of the commands
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/frankrap/deribit-api"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Environment variables overriding the config file
+const (
+	EnvAddr      = "DERIBIT_ADDR"
+	EnvApiKey    = "DERIBIT_API_KEY"
+	EnvSecretKey = "DERIBIT_SECRET_KEY"
+)
+
+// DefaultPath returns ~/.deribit.json
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".deribit.json")
+}
+
+// Load reads the JSON config file at path if it exists,
+// then applies the environment variables
+func Load(path string, getenv func(string) string) (*deribit.Configuration, error) {
+	cfg := &deribit.Configuration{}
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("%v: %v", path, err)
+			}
+		}
+	}
+	if v := getenv(EnvAddr); v != "" {
+		cfg.Addr = v
+	}
+	if v := getenv(EnvApiKey); v != "" {
+		cfg.ApiKey = v
+	}
+	if v := getenv(EnvSecretKey); v != "" {
+		cfg.SecretKey = v
+	}
+	return cfg, nil
+}
+
+// BaseURL returns the address of the real or test server, for the HTTP or WebSocket API
+func BaseURL(test bool, http bool) string {
+	switch {
+	case test && http:
+		return deribit.TestBaseHTTPURL
+	case test:
+		return deribit.TestBaseURL
+	case http:
+		return deribit.RealBaseHTTPURL
+	}
+	return deribit.RealBaseURL
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"github.com/frankrap/deribit-api"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	f, err := ioutil.TempFile("", "deribit")
+	assert.Nil(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString(`{"addr":"wss://test.deribit.com/ws/api/v2/","api_key":"file","secret_key":"file"}`)
+	f.Close()
+
+	env := map[string]string{EnvApiKey: "env"}
+	cfg, err := Load(f.Name(), func(key string) string { return env[key] })
+	assert.Nil(t, err)
+	assert.Equal(t, deribit.TestBaseURL, cfg.Addr)
+	assert.Equal(t, "env", cfg.ApiKey)
+	assert.Equal(t, "file", cfg.SecretKey)
+
+	_, err = Load(f.Name()+".missing", func(string) string { return "" })
+	assert.Nil(t, err)
+}
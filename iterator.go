@@ -0,0 +1,402 @@
+package deribit
+
+import (
+	"context"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/sourcegraph/jsonrpc2"
+	"time"
+)
+
+const (
+	// DefaultPageSize is the count requested per page by iterators
+	DefaultPageSize = 1000
+	// DefaultPageInterval is the minimum delay between the page requests of an iterator
+	DefaultPageInterval = 100 * time.Millisecond
+
+	// ErrCodeTooManyRequests is the error code of rate limited requests
+	ErrCodeTooManyRequests = 10028
+
+	maxPageRetries = 5
+)
+
+// IteratorCheckpoint is the position of an iterator after the last item returned by Next,
+// it can be saved as JSON and passed to Resume to continue from there
+type IteratorCheckpoint struct {
+	Seq          int    `json:"seq,omitempty"`
+	Timestamp    int64  `json:"timestamp,omitempty"`
+	Offset       int    `json:"offset,omitempty"`
+	Continuation string `json:"continuation,omitempty"`
+}
+
+// pageItem is an item of a page with the checkpoint following it
+type pageItem struct {
+	value      interface{}
+	checkpoint IteratorCheckpoint
+}
+
+// iterator pages through results with fetch, which returns the items of the page at
+// cursor and the cursor of the next page, or more false after the last page
+type iterator struct {
+	ctx      context.Context
+	fetch    func(cursor IteratorCheckpoint, count int) (items []pageItem, next IteratorCheckpoint, more bool, err error)
+	size     int
+	interval time.Duration
+
+	cursor     IteratorCheckpoint
+	checkpoint IteratorCheckpoint
+	items      []pageItem
+	item       interface{}
+	more       bool
+	last       time.Time
+	err        error
+}
+
+func newIterator(ctx context.Context, fetch func(IteratorCheckpoint, int) ([]pageItem, IteratorCheckpoint, bool, error)) iterator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return iterator{ctx: ctx, fetch: fetch, size: DefaultPageSize, interval: DefaultPageInterval, more: true}
+}
+
+// SetPageSize sets the count requested per page
+func (it *iterator) SetPageSize(size int) {
+	it.size = size
+}
+
+// SetPageInterval sets the minimum delay between page requests.
+// Rate limited requests back off from the larger of interval and DefaultPageInterval.
+func (it *iterator) SetPageInterval(interval time.Duration) {
+	it.interval = interval
+}
+
+// Resume continues the iteration after checkpoint, it must be called before Next
+func (it *iterator) Resume(checkpoint IteratorCheckpoint) {
+	it.cursor = checkpoint
+	it.checkpoint = checkpoint
+}
+
+// Checkpoint returns the position after the last item returned by Next
+func (it *iterator) Checkpoint() IteratorCheckpoint {
+	return it.checkpoint
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *iterator) Err() error {
+	return it.err
+}
+
+// Next advances to the next item, it returns false at the end or on error
+func (it *iterator) Next() bool {
+	for len(it.items) == 0 {
+		if !it.more || it.err != nil {
+			return false
+		}
+		it.err = it.fetchPage()
+	}
+	it.item = it.items[0].value
+	it.checkpoint = it.items[0].checkpoint
+	it.items = it.items[1:]
+	return true
+}
+
+// fetchPage fetches the next page, waiting for the page interval and retrying rate limited requests
+func (it *iterator) fetchPage() error {
+	delay := it.interval - time.Since(it.last)
+	for retry := 0; ; retry++ {
+		if err := it.wait(delay); err != nil {
+			return err
+		}
+		it.last = time.Now()
+		items, next, more, err := it.fetch(it.cursor, it.size)
+		if e, ok := err.(*jsonrpc2.Error); ok && e.Code == ErrCodeTooManyRequests && retry < maxPageRetries {
+			base := it.interval
+			if base < DefaultPageInterval {
+				base = DefaultPageInterval
+			}
+			delay = base << uint(retry+1)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		it.items = items
+		it.cursor = next
+		it.more = more
+		return nil
+	}
+}
+
+func (it *iterator) wait(delay time.Duration) error {
+	if delay <= 0 {
+		return it.ctx.Err()
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-it.ctx.Done():
+		return it.ctx.Err()
+	}
+}
+
+// millis returns t in milliseconds since epoch
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// TradesIterator iterates over the trades of an instrument in ascending trade_seq
+type TradesIterator struct {
+	iterator
+}
+
+// Trade returns the current trade
+func (it *TradesIterator) Trade() models.Trade {
+	return it.item.(models.Trade)
+}
+
+// TradesIterator returns an iterator over the trades of instrument between start and end.
+// The first page is requested by time, the next ones by start_seq.
+func (c *Client) TradesIterator(ctx context.Context, instrument string, start time.Time, end time.Time) *TradesIterator {
+	endMillis := millis(end)
+	return &TradesIterator{newIterator(ctx, func(cursor IteratorCheckpoint, count int) ([]pageItem, IteratorCheckpoint, bool, error) {
+		var result models.GetLastTradesResponse
+		var err error
+		if cursor.Seq == 0 {
+			result, err = c.GetLastTradesByInstrumentAndTime(&models.GetLastTradesByInstrumentAndTimeParams{
+				InstrumentName: instrument,
+				StartTimestamp: int(millis(start)),
+				EndTimestamp:   int(endMillis),
+				Count:          count,
+				IncludeOld:     true,
+				Sorting:        "asc",
+			})
+		} else {
+			result, err = c.GetLastTradesByInstrument(&models.GetLastTradesByInstrumentParams{
+				InstrumentName: instrument,
+				StartSeq:       cursor.Seq + 1,
+				Count:          count,
+				IncludeOld:     true,
+				Sorting:        "asc",
+			})
+		}
+		if err != nil {
+			return nil, cursor, false, err
+		}
+		var items []pageItem
+		for _, trade := range result.Trades {
+			if trade.Timestamp > endMillis {
+				return items, cursor, false, nil
+			}
+			if trade.TradeSeq <= cursor.Seq {
+				continue
+			}
+			cursor = IteratorCheckpoint{Seq: trade.TradeSeq, Timestamp: trade.Timestamp}
+			items = append(items, pageItem{trade, cursor})
+		}
+		return items, cursor, result.HasMore && cursor.Seq != 0, nil
+	})}
+}
+
+// UserTradesIterator iterates over the user trades of an instrument in ascending trade_seq
+type UserTradesIterator struct {
+	iterator
+}
+
+// Trade returns the current trade
+func (it *UserTradesIterator) Trade() models.UserTrade {
+	return it.item.(models.UserTrade)
+}
+
+// UserTradesIterator returns an iterator over the user trades of instrument between start and end.
+// The first page is requested by time, the next ones by start_seq.
+func (c *Client) UserTradesIterator(ctx context.Context, instrument string, start time.Time, end time.Time) *UserTradesIterator {
+	endMillis := millis(end)
+	return &UserTradesIterator{newIterator(ctx, func(cursor IteratorCheckpoint, count int) ([]pageItem, IteratorCheckpoint, bool, error) {
+		var result models.GetUserTradesResponse
+		var err error
+		if cursor.Seq == 0 {
+			result, err = c.GetUserTradesByInstrumentAndTime(&models.GetUserTradesByInstrumentAndTimeParams{
+				InstrumentName: instrument,
+				StartTimestamp: int(millis(start)),
+				EndTimestamp:   int(endMillis),
+				Count:          count,
+				IncludeOld:     true,
+				Sorting:        "asc",
+			})
+		} else {
+			result, err = c.GetUserTradesByInstrument(&models.GetUserTradesByInstrumentParams{
+				InstrumentName: instrument,
+				StartSeq:       cursor.Seq + 1,
+				Count:          count,
+				IncludeOld:     true,
+				Sorting:        "asc",
+			})
+		}
+		if err != nil {
+			return nil, cursor, false, err
+		}
+		var items []pageItem
+		for _, trade := range result.Trades {
+			if trade.Timestamp > endMillis {
+				return items, cursor, false, nil
+			}
+			if trade.TradeSeq <= cursor.Seq {
+				continue
+			}
+			cursor = IteratorCheckpoint{Seq: trade.TradeSeq, Timestamp: trade.Timestamp}
+			items = append(items, pageItem{trade, cursor})
+		}
+		return items, cursor, result.HasMore && cursor.Seq != 0, nil
+	})}
+}
+
+// OrderIterator iterates over the order history, newest first
+type OrderIterator struct {
+	iterator
+}
+
+// Order returns the current order
+func (it *OrderIterator) Order() models.Order {
+	return it.item.(models.Order)
+}
+
+// orderHistoryIterator pages by offset with get, skipping orders already returned
+// when new orders shift the history
+func orderHistoryIterator(ctx context.Context, get func(offset int, count int) ([]models.Order, error)) *OrderIterator {
+	seen := make(map[string]struct{})
+	return &OrderIterator{newIterator(ctx, func(cursor IteratorCheckpoint, count int) ([]pageItem, IteratorCheckpoint, bool, error) {
+		orders, err := get(cursor.Offset, count)
+		if err != nil {
+			return nil, cursor, false, err
+		}
+		var items []pageItem
+		for i, order := range orders {
+			if _, ok := seen[order.OrderID]; ok {
+				continue
+			}
+			seen[order.OrderID] = struct{}{}
+			items = append(items, pageItem{order, IteratorCheckpoint{Offset: cursor.Offset + i + 1}})
+		}
+		next := IteratorCheckpoint{Offset: cursor.Offset + len(orders)}
+		return items, next, len(orders) == count, nil
+	})}
+}
+
+// OrderHistoryByInstrumentIterator returns an iterator over the order history of instrument
+func (c *Client) OrderHistoryByInstrumentIterator(ctx context.Context, params *models.GetOrderHistoryByInstrumentParams) *OrderIterator {
+	p := *params
+	return orderHistoryIterator(ctx, func(offset int, count int) ([]models.Order, error) {
+		p.Offset, p.Count = offset, count
+		return c.GetOrderHistoryByInstrument(&p)
+	})
+}
+
+// OrderHistoryByCurrencyIterator returns an iterator over the order history of a currency
+func (c *Client) OrderHistoryByCurrencyIterator(ctx context.Context, params *models.GetOrderHistoryByCurrencyParams) *OrderIterator {
+	p := *params
+	return orderHistoryIterator(ctx, func(offset int, count int) ([]models.Order, error) {
+		p.Offset, p.Count = offset, count
+		return c.GetOrderHistoryByCurrency(&p)
+	})
+}
+
+// SettlementIterator iterates over the settlement history, newest first
+type SettlementIterator struct {
+	iterator
+}
+
+// Settlement returns the current settlement
+func (it *SettlementIterator) Settlement() models.Settlement {
+	return it.item.(models.Settlement)
+}
+
+// settlementIterator pages by continuation with get
+func settlementIterator(ctx context.Context, get func(continuation string, count int) (models.GetSettlementHistoryResponse, error)) *SettlementIterator {
+	return &SettlementIterator{newIterator(ctx, func(cursor IteratorCheckpoint, count int) ([]pageItem, IteratorCheckpoint, bool, error) {
+		result, err := get(cursor.Continuation, count)
+		if err != nil {
+			return nil, cursor, false, err
+		}
+		var items []pageItem
+		for i, settlement := range result.Settlements {
+			if i < cursor.Offset {
+				// returned before a resume
+				continue
+			}
+			items = append(items, pageItem{settlement, IteratorCheckpoint{Continuation: cursor.Continuation, Offset: i + 1}})
+		}
+		more := result.Continuation != "" && result.Continuation != "none" && len(result.Settlements) > 0
+		return items, IteratorCheckpoint{Continuation: result.Continuation}, more, nil
+	})}
+}
+
+// SettlementHistoryByInstrumentIterator returns an iterator over the settlement history of instrument
+func (c *Client) SettlementHistoryByInstrumentIterator(ctx context.Context, params *models.GetSettlementHistoryByInstrumentParams) *SettlementIterator {
+	p := *params
+	return settlementIterator(ctx, func(continuation string, count int) (models.GetSettlementHistoryResponse, error) {
+		p.Continuation, p.Count = continuation, count
+		return c.GetSettlementHistoryByInstrument(&p)
+	})
+}
+
+// SettlementHistoryByCurrencyIterator returns an iterator over the settlement history of a currency
+func (c *Client) SettlementHistoryByCurrencyIterator(ctx context.Context, params *models.GetSettlementHistoryByCurrencyParams) *SettlementIterator {
+	p := *params
+	return settlementIterator(ctx, func(continuation string, count int) (models.GetSettlementHistoryResponse, error) {
+		p.Continuation, p.Count = continuation, count
+		return c.GetSettlementHistoryByCurrency(&p)
+	})
+}
+
+// TransferIterator iterates over the transfers of a currency
+type TransferIterator struct {
+	iterator
+}
+
+// Transfer returns the current transfer
+func (it *TransferIterator) Transfer() models.Transfer {
+	return it.item.(models.Transfer)
+}
+
+// TransfersIterator returns an iterator over the transfers of currency
+func (c *Client) TransfersIterator(ctx context.Context, currency string) *TransferIterator {
+	return &TransferIterator{newIterator(ctx, func(cursor IteratorCheckpoint, count int) ([]pageItem, IteratorCheckpoint, bool, error) {
+		result, err := c.GetTransfers(&models.GetTransfersParams{Currency: currency, Count: count, Offset: cursor.Offset})
+		if err != nil {
+			return nil, cursor, false, err
+		}
+		var items []pageItem
+		for i, transfer := range result.Data {
+			items = append(items, pageItem{transfer, IteratorCheckpoint{Offset: cursor.Offset + i + 1}})
+		}
+		next := IteratorCheckpoint{Offset: cursor.Offset + len(result.Data)}
+		return items, next, len(result.Data) > 0 && next.Offset < result.Count, nil
+	})}
+}
+
+// DepositIterator iterates over the deposits of a currency
+type DepositIterator struct {
+	iterator
+}
+
+// Deposit returns the current deposit
+func (it *DepositIterator) Deposit() models.Deposit {
+	return it.item.(models.Deposit)
+}
+
+// DepositsIterator returns an iterator over the deposits of currency
+func (c *Client) DepositsIterator(ctx context.Context, currency string) *DepositIterator {
+	return &DepositIterator{newIterator(ctx, func(cursor IteratorCheckpoint, count int) ([]pageItem, IteratorCheckpoint, bool, error) {
+		result, err := c.GetDeposits(&models.GetDepositsParams{Currency: currency, Count: count, Offset: cursor.Offset})
+		if err != nil {
+			return nil, cursor, false, err
+		}
+		var items []pageItem
+		for i, deposit := range result.Data {
+			items = append(items, pageItem{deposit, IteratorCheckpoint{Offset: cursor.Offset + i + 1}})
+		}
+		next := IteratorCheckpoint{Offset: cursor.Offset + len(result.Data)}
+		return items, next, len(result.Data) > 0 && next.Offset < result.Count, nil
+	})}
+}
@@ -0,0 +1,174 @@
+package deribit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// handleTrades serves 25 trades, one per second from 1000000, by time and by start_seq
+func handleTrades(server *deribittest.Server) {
+	var trades []models.Trade
+	for i := 1; i <= 25; i++ {
+		trades = append(trades, models.Trade{TradeSeq: i, Timestamp: int64(1000000 + i*1000), InstrumentName: "BTC-PERPETUAL"})
+	}
+	page := func(from func(models.Trade) bool, count int) models.GetLastTradesResponse {
+		result := models.GetLastTradesResponse{Trades: []models.Trade{}}
+		for _, trade := range trades {
+			if !from(trade) {
+				continue
+			}
+			if len(result.Trades) == count {
+				result.HasMore = true
+				break
+			}
+			result.Trades = append(result.Trades, trade)
+		}
+		return result
+	}
+	var limited int32
+	server.Handle("public/get_last_trades_by_instrument_and_time", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		if atomic.AddInt32(&limited, 1) == 1 {
+			return nil, deribittest.Error(ErrCodeTooManyRequests, "too_many_requests")
+		}
+		var p models.GetLastTradesByInstrumentAndTimeParams
+		json.Unmarshal(params, &p)
+		return page(func(t models.Trade) bool {
+			return t.Timestamp >= int64(p.StartTimestamp) && t.Timestamp <= int64(p.EndTimestamp)
+		}, p.Count), nil
+	})
+	server.Handle("public/get_last_trades_by_instrument", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		var p models.GetLastTradesByInstrumentParams
+		json.Unmarshal(params, &p)
+		// overlap with the previous page to exercise the dedupe
+		return page(func(t models.Trade) bool { return t.TradeSeq >= p.StartSeq-1 }, p.Count), nil
+	})
+}
+
+func TestTradesIterator(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	handleTrades(server)
+	client := New(&Configuration{Addr: server.HTTPURL})
+
+	start := time.Unix(1003, 0)
+	end := time.Unix(1020, 0)
+	it := client.TradesIterator(context.Background(), "BTC-PERPETUAL", start, end)
+	it.SetPageSize(5)
+	it.SetPageInterval(time.Millisecond)
+	var seqs []int
+	for len(seqs) < 7 && it.Next() {
+		seqs = append(seqs, it.Trade().TradeSeq)
+	}
+	assert.Nil(t, it.Err())
+	checkpoint := it.Checkpoint()
+	assert.Equal(t, 9, checkpoint.Seq)
+
+	it = client.TradesIterator(context.Background(), "BTC-PERPETUAL", start, end)
+	it.SetPageSize(5)
+	it.SetPageInterval(time.Millisecond)
+	it.Resume(checkpoint)
+	for it.Next() {
+		seqs = append(seqs, it.Trade().TradeSeq)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []int{3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, seqs)
+}
+
+func TestTradesIterator_Backoff(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	handleTrades(server)
+	client := New(&Configuration{Addr: server.HTTPURL})
+
+	it := client.TradesIterator(context.Background(), "BTC-PERPETUAL", time.Unix(1003, 0), time.Unix(1020, 0))
+	it.SetPageInterval(0)
+	start := time.Now()
+	assert.True(t, it.Next())
+	assert.Nil(t, it.Err())
+	assert.True(t, time.Since(start) >= 2*DefaultPageInterval, "rate limited request retried without backoff")
+	assert.Equal(t, 2, server.CallCount("public/get_last_trades_by_instrument_and_time"))
+}
+
+func TestTradesIterator_Cancel(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.RespondError("public/get_last_trades_by_instrument_and_time", ErrCodeTooManyRequests, "too_many_requests")
+	client := New(&Configuration{Addr: server.HTTPURL})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	it := client.TradesIterator(ctx, "BTC-PERPETUAL", time.Unix(0, 0), time.Now())
+	assert.False(t, it.Next())
+	assert.Equal(t, context.DeadlineExceeded, it.Err())
+}
+
+func TestTransfersIterator(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.Handle("private/get_transfers", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		var p models.GetTransfersParams
+		json.Unmarshal(params, &p)
+		result := models.GetTransfersResponse{Count: 12, Data: []models.Transfer{}}
+		for i := p.Offset; i < p.Offset+p.Count && i < 12; i++ {
+			result.Data = append(result.Data, models.Transfer{ID: i})
+		}
+		return result, nil
+	})
+	client := New(&Configuration{Addr: server.HTTPURL, ApiKey: "key", SecretKey: "secret"})
+
+	it := client.TransfersIterator(context.Background(), "BTC")
+	it.SetPageSize(5)
+	it.SetPageInterval(0)
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Transfer().ID)
+	}
+	assert.Nil(t, it.Err())
+	assert.Len(t, ids, 12)
+	assert.Equal(t, 11, ids[11])
+	assert.Equal(t, 3, server.CallCount("private/get_transfers"))
+}
+
+func TestSettlementIterator(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.Handle("private/get_settlement_history_by_currency", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		var p models.GetSettlementHistoryByCurrencyParams
+		json.Unmarshal(params, &p)
+		page, _ := strconv.Atoi(p.Continuation)
+		result := models.GetSettlementHistoryResponse{Continuation: strconv.Itoa(page + 1)}
+		if page == 2 {
+			result.Continuation = "none"
+		}
+		for i := 0; i < 2; i++ {
+			result.Settlements = append(result.Settlements, models.Settlement{Timestamp: int64(page*2 + i)})
+		}
+		return result, nil
+	})
+	client := New(&Configuration{Addr: server.HTTPURL, ApiKey: "key", SecretKey: "secret"})
+
+	it := client.SettlementHistoryByCurrencyIterator(context.Background(), &models.GetSettlementHistoryByCurrencyParams{Currency: "BTC"})
+	it.SetPageInterval(0)
+	assert.True(t, it.Next())
+	assert.True(t, it.Next())
+	assert.True(t, it.Next())
+	checkpoint := it.Checkpoint()
+	assert.Equal(t, IteratorCheckpoint{Continuation: "1", Offset: 1}, checkpoint)
+
+	it = client.SettlementHistoryByCurrencyIterator(context.Background(), &models.GetSettlementHistoryByCurrencyParams{Currency: "BTC"})
+	it.SetPageInterval(0)
+	it.Resume(checkpoint)
+	var timestamps []int64
+	for it.Next() {
+		timestamps = append(timestamps, it.Settlement().Timestamp)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []int64{3, 4, 5}, timestamps)
+}
@@ -1,20 +1,27 @@
 package models
 
 type BookSummary struct {
-	Volume            float64 `json:"volume"`
-	UnderlyingPrice   float64 `json:"underlying_price"`
-	UnderlyingIndex   string  `json:"underlying_index"`
-	QuoteCurrency     string  `json:"quote_currency"`
-	OpenInterest      float64 `json:"open_interest"`
-	MidPrice          float64 `json:"mid_price"`
-	MarkPrice         float64 `json:"mark_price"`
-	Low               float64 `json:"low"`
-	Last              float64 `json:"last"`
-	InterestRate      float64 `json:"interest_rate"`
-	InstrumentName    string  `json:"instrument_name"`
-	High              float64 `json:"high"`
-	CreationTimestamp int64   `json:"creation_timestamp"`
-	BidPrice          float64 `json:"bid_price"`
-	BaseCurrency      string  `json:"base_currency"`
-	AskPrice          float64 `json:"ask_price"`
+	AskPrice               float64 `json:"ask_price"`
+	BaseCurrency           string  `json:"base_currency"`
+	BidPrice               float64 `json:"bid_price"`
+	CreationTimestamp      int64   `json:"creation_timestamp"`
+	CurrentFunding         float64 `json:"current_funding,omitempty"`
+	EstimatedDeliveryPrice float64 `json:"estimated_delivery_price"`
+	Funding8H              float64 `json:"funding_8h,omitempty"`
+	High                   float64 `json:"high"`
+	InstrumentName         string  `json:"instrument_name"`
+	InterestRate           float64 `json:"interest_rate"`
+	Last                   float64 `json:"last"`
+	Low                    float64 `json:"low"`
+	MarkIv                 float64 `json:"mark_iv,omitempty"`
+	MarkPrice              float64 `json:"mark_price"`
+	MidPrice               float64 `json:"mid_price"`
+	OpenInterest           float64 `json:"open_interest"`
+	PriceChange            float64 `json:"price_change"`
+	QuoteCurrency          string  `json:"quote_currency"`
+	UnderlyingIndex        string  `json:"underlying_index"`
+	UnderlyingPrice        float64 `json:"underlying_price"`
+	Volume                 float64 `json:"volume"`
+	VolumeNotional         float64 `json:"volume_notional,omitempty"`
+	VolumeUsd              float64 `json:"volume_usd"`
 }
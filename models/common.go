@@ -1,9 +1,16 @@
 package models
 
-// Direction direction, `buy` or `sell`
+// APIVersion is the version of the Deribit API the models follow,
+// testdata/v<APIVersion> holds responses decoded against them
+const APIVersion = "2.1.1"
+
+// Direction direction, `buy` or `sell`, or `zero` of a closed position
+type Direction string
+
 const (
-	DirectionBuy  = "buy"
-	DirectionSell = "sell"
+	DirectionBuy  Direction = "buy"
+	DirectionSell Direction = "sell"
+	DirectionZero Direction = "zero"
 )
 
 // OrderState order state, `"open"`, `"filled"`, `"rejected"`, `"cancelled"`, `"untriggered"`
@@ -15,17 +22,83 @@ const (
 	OrderStateUntriggered = "untriggered"
 )
 
-// OrderType order type, `"limit"`, `"market"`, `"stop_limit"`, `"stop_market"`
+// OrderType order type, `"limit"`, `"market"`, `"stop_limit"`, `"stop_market"`, `"take_limit"`, `"take_market"`,
+// `"market_limit"`, `"trailing_stop"`, or `"liquidation"` of trades of liquidations
+type OrderType string
+
+const (
+	OrderTypeLimit        OrderType = "limit"
+	OrderTypeMarket       OrderType = "market"
+	OrderTypeStopLimit    OrderType = "stop_limit"
+	OrderTypeStopMarket   OrderType = "stop_market"
+	OrderTypeTakeLimit    OrderType = "take_limit"
+	OrderTypeTakeMarket   OrderType = "take_market"
+	OrderTypeMarketLimit  OrderType = "market_limit"
+	OrderTypeTrailingStop OrderType = "trailing_stop"
+	OrderTypeLiquidation  OrderType = "liquidation"
+)
+
+// TimeInForce time in force of an order, `"good_til_cancelled"`, `"good_til_day"`, `"fill_or_kill"`,
+// `"immediate_or_cancel"`
+type TimeInForce string
+
+const (
+	TimeInForceGoodTilCancelled  TimeInForce = "good_til_cancelled"
+	TimeInForceGoodTilDay        TimeInForce = "good_til_day"
+	TimeInForceFillOrKill        TimeInForce = "fill_or_kill"
+	TimeInForceImmediateOrCancel TimeInForce = "immediate_or_cancel"
+)
+
+// TriggerType trigger type of stop orders, `"index_price"`, `"mark_price"`, `"last_price"`
+type TriggerType string
+
+const (
+	TriggerTypeIndexPrice TriggerType = "index_price"
+	TriggerTypeMarkPrice  TriggerType = "mark_price"
+	TriggerTypeLastPrice  TriggerType = "last_price"
+)
+
+// Advanced advanced option order type, the price is in `"usd"` or in implied volatility `"implv"`
+type Advanced string
+
+const (
+	AdvancedUSD   Advanced = "usd"
+	AdvancedImplV Advanced = "implv"
+)
+
+// Kind instrument kind, `"future"`, `"option"`, `"spot"`, `"future_combo"`, `"option_combo"`,
+// or `"any"` in params accepting every kind
+type Kind string
+
+const (
+	KindFuture      Kind = "future"
+	KindOption      Kind = "option"
+	KindSpot        Kind = "spot"
+	KindFutureCombo Kind = "future_combo"
+	KindOptionCombo Kind = "option_combo"
+	KindAny         Kind = "any"
+)
+
+// OptionType option type, `"call"`, `"put"`
+const (
+	OptionTypeCall = "call"
+	OptionTypePut  = "put"
+)
+
+// SettlementPeriod settlement period, `"perpetual"`, `"day"`, `"week"`, `"month"`
 const (
-	OrderTypeLimit      = "limit"
-	OrderTypeMarket     = "market"
-	OrderTypeStopLimit  = "stop_limit"
-	OrderTypeStopMarket = "stop_market"
+	SettlementPeriodPerpetual = "perpetual"
+	SettlementPeriodDay       = "day"
+	SettlementPeriodWeek      = "week"
+	SettlementPeriodMonth     = "month"
 )
 
-// TriggerType trigger type, `"index_price"`, `"mark_price"`, `"last_price"`
+// InstrumentState instrument state of instrument.state notifications,
+// `"created"`, `"started"`, `"settled"`, `"closed"`, `"terminated"`
 const (
-	TriggerTypeIndexPrice = "index_price"
-	TriggerTypeMarkPrice  = "mark_price"
-	TriggerTypeLastPrice  = "last_price"
+	InstrumentStateCreated    = "created"
+	InstrumentStateStarted    = "started"
+	InstrumentStateSettled    = "settled"
+	InstrumentStateClosed     = "closed"
+	InstrumentStateTerminated = "terminated"
 )
@@ -0,0 +1,272 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number, coef * 10^-scale.
+// Decoded from JSON it keeps its wire text and encodes back to it unchanged.
+// The zero value is 0.
+type Decimal struct {
+	coef   *big.Int
+	scale  int32
+	text   string // wire text, empty for computed values
+	quoted bool   // the wire text was a JSON string
+}
+
+// maxDecimalExponent bounds the exponent of parsed decimals, whose coefficients
+// are otherwise scaled to 10^exponent digits
+const maxDecimalExponent = 1000
+
+var bigTen = big.NewInt(10)
+
+// NewDecimal returns coef * 10^-scale, e.g. NewDecimal(125, 1) is 12.5
+func NewDecimal(coef int64, scale int32) Decimal {
+	return Decimal{coef: big.NewInt(coef), scale: scale}.normalize()
+}
+
+// NewDecimalFromFloat returns the decimal of the shortest text which parses to f,
+// the wire text of any number with at most 15 significant digits decoded to f
+func NewDecimalFromFloat(f float64) Decimal {
+	d, _ := ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+	d.text = ""
+	return d.normalize()
+}
+
+// ParseDecimal parses a decimal number such as "-12.5", "0.00025" or "1e-5"
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		exp, err = strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		if exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("decimal %q out of range", s)
+		}
+		mantissa = s[:i]
+	}
+	scale := int64(0)
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		scale = int64(len(mantissa) - i - 1)
+		mantissa = mantissa[:i] + mantissa[i+1:]
+	}
+	digits := strings.TrimLeft(mantissa, "+-")
+	if digits == "" || len(mantissa)-len(digits) > 1 || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	coef, _ := new(big.Int).SetString(mantissa, 10)
+	scale -= exp
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: int32(scale), text: s}, nil
+}
+
+// MustParseDecimal is ParseDecimal panicking on invalid input, for constants
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) bigCoef() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale returns the coefficient of d at scale, which must not be below the scale of d
+func (d Decimal) rescale(scale int32) *big.Int {
+	coef := new(big.Int).Set(d.bigCoef())
+	if scale > d.scale {
+		coef.Mul(coef, pow10(scale-d.scale))
+	}
+	return coef
+}
+
+// normalize drops the trailing zeros and the wire text
+func (d Decimal) normalize() Decimal {
+	coef := new(big.Int).Set(d.bigCoef())
+	scale := d.scale
+	q, r := new(big.Int), new(big.Int)
+	for scale > 0 && coef.Sign() != 0 {
+		q.QuoRem(coef, bigTen, r)
+		if r.Sign() != 0 {
+			break
+		}
+		coef.Set(q)
+		scale--
+	}
+	if coef.Sign() == 0 {
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+// Add returns d + e
+func (d Decimal) Add(e Decimal) Decimal {
+	scale := maxScale(d, e)
+	return Decimal{coef: new(big.Int).Add(d.rescale(scale), e.rescale(scale)), scale: scale}.normalize()
+}
+
+// Sub returns d - e
+func (d Decimal) Sub(e Decimal) Decimal {
+	scale := maxScale(d, e)
+	return Decimal{coef: new(big.Int).Sub(d.rescale(scale), e.rescale(scale)), scale: scale}.normalize()
+}
+
+// Mul returns d * e
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.bigCoef(), e.bigCoef()), scale: d.scale + e.scale}.normalize()
+}
+
+// Div returns d / e rounded half away from zero to places decimals, it panics if e is zero
+func (d Decimal) Div(e Decimal, places int32) Decimal {
+	// d/e = dc*10^es / (ec*10^ds), scaled by 10^(places+1) to round on the extra digit
+	num := new(big.Int).Mul(d.bigCoef(), pow10(e.scale+places+1))
+	den := new(big.Int).Mul(e.bigCoef(), pow10(d.scale))
+	q := new(big.Int).Quo(num, den)
+	return Decimal{coef: q, scale: places + 1}.Round(places)
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.bigCoef()), scale: d.scale}.normalize()
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.bigCoef()), scale: d.scale}.normalize()
+}
+
+// Round returns d rounded half away from zero to places decimals
+func (d Decimal) Round(places int32) Decimal {
+	if d.scale <= places {
+		return d.normalize()
+	}
+	unit := pow10(d.scale - places)
+	q, r := new(big.Int).QuoRem(d.bigCoef(), unit, new(big.Int))
+	r.Abs(r).Mul(r, big.NewInt(2))
+	if r.Cmp(unit) >= 0 {
+		q.Add(q, big.NewInt(int64(d.bigCoef().Sign())))
+	}
+	return Decimal{coef: q, scale: places}.normalize()
+}
+
+// Truncate returns d rounded towards zero to places decimals
+func (d Decimal) Truncate(places int32) Decimal {
+	if d.scale <= places {
+		return d.normalize()
+	}
+	return Decimal{coef: new(big.Int).Quo(d.bigCoef(), pow10(d.scale-places)), scale: places}.normalize()
+}
+
+// IsMultipleOf reports whether d is a multiple of step, e.g. of a tick size
+func (d Decimal) IsMultipleOf(step Decimal) bool {
+	if step.Sign() == 0 {
+		return d.Sign() == 0
+	}
+	scale := maxScale(d, step)
+	return new(big.Int).Rem(d.rescale(scale), step.rescale(scale)).Sign() == 0
+}
+
+// Cmp returns -1, 0 or +1 if d is less than, equal to or greater than e
+func (d Decimal) Cmp(e Decimal) int {
+	scale := maxScale(d, e)
+	return d.rescale(scale).Cmp(e.rescale(scale))
+}
+
+// Equal reports whether d and e are the same number, 1.50 equals 1.5
+func (d Decimal) Equal(e Decimal) bool {
+	return d.Cmp(e) == 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d
+func (d Decimal) Sign() int {
+	return d.bigCoef().Sign()
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Float64 returns the nearest float64 of d
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns the wire text of d if decoded, its plain decimal text otherwise
+func (d Decimal) String() string {
+	if d.text != "" {
+		return d.text
+	}
+	s := new(big.Int).Abs(d.bigCoef()).String()
+	if d.scale > 0 {
+		if n := int(d.scale) + 1 - len(s); n > 0 {
+			s = strings.Repeat("0", n) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if d.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// MarshalJSON encodes d as its wire text, a number unless decoded from a string
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if d.quoted {
+		return []byte(strconv.Quote(d.String())), nil
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string, keeping its text.
+// "market_price", the price of market stop orders, decodes to 0 like Price does
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if string(data) == `"market_price"` {
+		*d = Decimal{}
+		return nil
+	}
+	s, quoted := string(data), false
+	if len(data) > 1 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return err
+		}
+		s, quoted = unquoted, true
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	v.quoted = quoted
+	*d = v
+	return nil
+}
+
+func maxScale(d Decimal, e Decimal) int32 {
+	if d.scale > e.scale {
+		return d.scale
+	}
+	return e.scale
+}
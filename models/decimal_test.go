@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDecimal_JSON(t *testing.T) {
+	var v struct {
+		Price   Decimal  `json:"price"`
+		Amount  Decimal  `json:"amount"`
+		Balance Decimal  `json:"balance"`
+		Fee     *Decimal `json:"fee"`
+	}
+	data := `{"price":6000.50,"amount":"0.10","balance":1.2345678901234567890123,"fee":null}`
+	assert.Nil(t, json.Unmarshal([]byte(data), &v))
+	assert.Equal(t, "6000.50", v.Price.String())
+	assert.True(t, v.Amount.Equal(NewDecimal(1, 1)))
+	assert.Equal(t, "1.2345678901234567890123", v.Balance.String())
+
+	out, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.Equal(t, data, string(out))
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"price":"abc"}`), &v))
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	d := MustParseDecimal
+	assert.Equal(t, "0.3", d("0.1").Add(d("0.2")).String())
+	assert.Equal(t, "-0.1", d("0.1").Sub(d("0.2")).String())
+	assert.Equal(t, "0.0025", d("0.05").Mul(d("0.05")).String())
+	assert.Equal(t, "0.67", d("2").Div(d("3"), 2).String())
+	assert.Equal(t, "-0.67", d("-2").Div(d("3"), 2).String())
+	assert.Equal(t, "1.5e3", d("1.5e3").String(), "parsed text is kept")
+	assert.Equal(t, "1500", d("1.5e3").Add(Decimal{}).String())
+	assert.Equal(t, "0.00001", d("1e-5").Add(Decimal{}).String())
+	assert.Equal(t, "2.5", d("2.45").Round(1).String())
+	assert.Equal(t, "-2.5", d("-2.45").Round(1).String())
+	assert.Equal(t, "2.4", d("2.45").Truncate(1).String())
+	assert.True(t, d("6000.5").IsMultipleOf(d("0.5")))
+	assert.False(t, d("0.0126").IsMultipleOf(d("0.0005")))
+	assert.Equal(t, 1, d("1.01").Cmp(d("1.001")))
+	assert.Equal(t, "0", d("-0.00").Add(Decimal{}).String())
+	assert.Equal(t, 0.3, d("0.1").Add(d("0.2")).Float64())
+
+	for _, s := range []string{"", "-", "1.2.3", "1e", "--1", "0x10", "1e999999999", "1e-2000"} {
+		_, err := ParseDecimal(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestDecimal_Models(t *testing.T) {
+	data := `{"balance":0.30000000000000004,"equity":1.10,"currency":"BTC"}`
+	var summary AccountSummary
+	assert.Nil(t, json.Unmarshal([]byte(data), &summary))
+	var decimals PortfolioDecimals
+	assert.Nil(t, json.Unmarshal([]byte(data), &decimals))
+	assert.Equal(t, 1.1, summary.Equity)
+	assert.Equal(t, "1.10", decimals.Equity.String(), "the wire text is kept")
+	assert.Equal(t, "3.3", decimals.Equity.Add(MustParseDecimal("2.2")).String())
+	assert.Equal(t, "0.30000000000000004", decimals.Balance.String())
+
+	var order OrderDecimals
+	assert.Nil(t, json.Unmarshal([]byte(`{"order_id":"1","price":"market_price","amount":10}`), &order))
+	assert.True(t, order.Price.IsZero())
+	assert.Equal(t, "10", order.Amount.String())
+}
@@ -0,0 +1,8 @@
+package models
+
+// DepositDecimals are the numbers of a Deposit decoded exactly
+type DepositDecimals struct {
+	Amount        Decimal `json:"amount"`
+	Currency      string  `json:"currency"`
+	TransactionID string  `json:"transaction_id"`
+}
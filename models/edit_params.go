@@ -1,10 +1,13 @@
+// Code generated by cmd/gen from cmd/gen/deribit_api.json. DO NOT EDIT.
+
 package models
 
 type EditParams struct {
-	OrderID   string  `json:"order_id"`
-	Amount    float64 `json:"amount"`
-	Price     float64 `json:"price"`
-	PostOnly  bool    `json:"post_only,omitempty"`
-	Advanced  string  `json:"advanced,omitempty"`
-	StopPrice float64 `json:"stop_price,omitempty"`
+	OrderID        string   `json:"order_id"`
+	Amount         float64  `json:"amount"`
+	Price          float64  `json:"price"`
+	PostOnly       bool     `json:"post_only,omitempty"`
+	RejectPostOnly bool     `json:"reject_post_only,omitempty"`
+	Advanced       Advanced `json:"advanced,omitempty"`
+	StopPrice      float64  `json:"stop_price,omitempty"`
 }
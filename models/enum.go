@@ -0,0 +1,60 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Known enum values. Encoding and decoding accept any value, the exchange may add
+// new ones, only the params of orders are checked by their Validate method
+var (
+	directions   = []string{string(DirectionBuy), string(DirectionSell), string(DirectionZero)}
+	orderTypes   = []string{string(OrderTypeLimit), string(OrderTypeMarket), string(OrderTypeStopLimit), string(OrderTypeStopMarket), string(OrderTypeTakeLimit), string(OrderTypeTakeMarket), string(OrderTypeMarketLimit), string(OrderTypeTrailingStop), string(OrderTypeLiquidation)}
+	timeInForces = []string{string(TimeInForceGoodTilCancelled), string(TimeInForceGoodTilDay), string(TimeInForceFillOrKill), string(TimeInForceImmediateOrCancel)}
+	triggerTypes = []string{string(TriggerTypeIndexPrice), string(TriggerTypeMarkPrice), string(TriggerTypeLastPrice)}
+	advanceds    = []string{string(AdvancedUSD), string(AdvancedImplV)}
+	kinds        = []string{string(KindFuture), string(KindOption), string(KindSpot), string(KindFutureCombo), string(KindOptionCombo), string(KindAny)}
+)
+
+// Valid reports whether d is a known direction
+func (d Direction) Valid() bool {
+	return validEnum(string(d), directions)
+}
+
+// Valid reports whether t is a known order type
+func (t OrderType) Valid() bool {
+	return validEnum(string(t), orderTypes)
+}
+
+// Valid reports whether t is a known time in force
+func (t TimeInForce) Valid() bool {
+	return validEnum(string(t), timeInForces)
+}
+
+// Valid reports whether t is a known trigger type
+func (t TriggerType) Valid() bool {
+	return validEnum(string(t), triggerTypes)
+}
+
+// Valid reports whether a is a known advanced option order type
+func (a Advanced) Valid() bool {
+	return validEnum(string(a), advanceds)
+}
+
+// Valid reports whether k is a known instrument kind
+func (k Kind) Valid() bool {
+	return validEnum(string(k), kinds)
+}
+
+func validEnum(s string, values []string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func enumError(name string, s string, values []string) error {
+	return fmt.Errorf("invalid %v %q, want one of %v", name, s, strings.Join(values, ", "))
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEnum(t *testing.T) {
+	assert.True(t, DirectionSell.Valid())
+	assert.False(t, Direction("").Valid())
+	assert.True(t, KindAny.Valid())
+	assert.False(t, OrderType("limt").Valid())
+
+	data, err := json.Marshal(BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: OrderTypeStopLimit, Trigger: TriggerTypeMarkPrice})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"instrument_name":"BTC-PERPETUAL","amount":10,"type":"stop_limit","trigger":"mark_price"}`, string(data))
+
+	assert.True(t, OrderTypeTakeLimit.Valid())
+	assert.True(t, OrderTypeTakeMarket.Valid())
+	assert.True(t, OrderTypeMarketLimit.Valid())
+	assert.True(t, OrderTypeTrailingStop.Valid())
+	assert.True(t, TimeInForceGoodTilDay.Valid())
+	assert.True(t, KindFutureCombo.Valid())
+	assert.True(t, KindOptionCombo.Valid())
+
+	var order Order
+	assert.Nil(t, json.Unmarshal([]byte(`{"direction":"sell","order_type":"market","time_in_force":"fill_or_kill"}`), &order))
+	assert.Equal(t, DirectionSell, order.Direction)
+	assert.Equal(t, TimeInForceFillOrKill, order.TimeInForce)
+
+	assert.Nil(t, json.Unmarshal([]byte(`{"order_type":"take_limit","time_in_force":"good_til_day"}`), &order))
+	assert.Equal(t, OrderTypeTakeLimit, order.OrderType)
+	assert.Equal(t, TimeInForceGoodTilDay, order.TimeInForce)
+	var instrument Instrument
+	assert.Nil(t, json.Unmarshal([]byte(`{"instrument_name":"BTC-FS-PERP_29MAY20","kind":"future_combo"}`), &instrument))
+	assert.Equal(t, KindFutureCombo, instrument.Kind)
+
+	// values added by the exchange round-trip, and are only rejected by Validate
+	assert.Nil(t, json.Unmarshal([]byte(`{"order_type":"new_type","direction":"sell"}`), &order))
+	assert.Equal(t, OrderType("new_type"), order.OrderType)
+	assert.False(t, order.OrderType.Valid())
+	data, err = json.Marshal(&order)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"order_type":"new_type"`)
+	assert.EqualError(t, (&BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: order.OrderType}).Validate(),
+		`invalid type "new_type", want one of limit, market, stop_limit, stop_market, take_limit, take_market, market_limit, trailing_stop`)
+	assert.NotNil(t, (&BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000, TimeInForce: "good_till_cancelled"}).Validate())
+}
+
+func TestBuyParams_Validate(t *testing.T) {
+	valid := []BuyParams{
+		{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000},
+		{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: OrderTypeMarket},
+		{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: OrderTypeStopMarket, StopPrice: 5000, Trigger: TriggerTypeIndexPrice},
+		{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: OrderTypeTakeLimit, Price: 7000, StopPrice: 7000, TimeInForce: TimeInForceGoodTilDay},
+		{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: OrderTypeMarketLimit},
+		{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: OrderTypeTrailingStop, Trigger: TriggerTypeIndexPrice},
+		{InstrumentName: "BTC-29MAY20-6000-C", Amount: 1, Price: 0.8, Advanced: AdvancedImplV, PostOnly: true},
+	}
+	for _, p := range valid {
+		assert.Nil(t, p.Validate(), "%+v", p)
+	}
+	invalid := []struct {
+		params BuyParams
+		err    string
+	}{
+		{BuyParams{Amount: 10, Price: 6000}, "instrument_name is required"},
+		{BuyParams{InstrumentName: "BTC-PERPETUAL", Price: 6000}, "amount 0 is not positive"},
+		{BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10}, "price is required by limit orders"},
+		{BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: OrderTypeLiquidation},
+			`invalid type "liquidation", want one of limit, market, stop_limit, stop_market, take_limit, take_market, market_limit, trailing_stop`},
+		{BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: OrderTypeTakeMarket}, "stop_price is required by take_market orders"},
+		{BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: OrderTypeMarket, PostOnly: true},
+			"post_only is only allowed in limit orders, not in market orders"},
+		{BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000, RejectPostOnly: true},
+			"reject_post_only is only allowed in post_only orders"},
+		{BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000, Trigger: TriggerTypeLastPrice},
+			"stop_price and trigger are only allowed in stop orders, not in limit orders"},
+		{BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000, TimeInForce: "gtc"},
+			`invalid time_in_force "gtc", want one of good_til_cancelled, good_til_day, fill_or_kill, immediate_or_cancel`},
+	}
+	for _, c := range invalid {
+		assert.EqualError(t, c.params.Validate(), c.err)
+	}
+	assert.NotNil(t, (&ClosePositionParams{InstrumentName: "BTC-PERPETUAL", Type: OrderTypeStopLimit}).Validate())
+	assert.Nil(t, (&ClosePositionParams{InstrumentName: "BTC-PERPETUAL", Type: OrderTypeMarket}).Validate())
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// golden maps the files of testdata/v<version> to the models they decode into
+var golden = map[string]func() interface{}{
+	"account_summary":           func() interface{} { return new(AccountSummary) },
+	"book_summary":              func() interface{} { return new([]BookSummary) },
+	"currency":                  func() interface{} { return new([]Currency) },
+	"get_deposits":              func() interface{} { return new(GetDepositsResponse) },
+	"get_last_trades":           func() interface{} { return new(GetLastTradesResponse) },
+	"get_order_book":            func() interface{} { return new(GetOrderBookResponse) },
+	"get_settlement_history":    func() interface{} { return new(GetSettlementHistoryResponse) },
+	"get_transfers":             func() interface{} { return new(GetTransfersResponse) },
+	"get_user_trades":           func() interface{} { return new(GetUserTradesResponse) },
+	"instrument_future":         func() interface{} { return new(Instrument) },
+	"instrument_option":         func() interface{} { return new(Instrument) },
+	"order":                     func() interface{} { return new(Order) },
+	"portfolio_notification":    func() interface{} { return new(PortfolioNotification) },
+	"position":                  func() interface{} { return new(Position) },
+	"ticker":                    func() interface{} { return new(TickerResponse) },
+	"ticker_notification":       func() interface{} { return new(TickerNotification) },
+	"user_changes_notification": func() interface{} { return new(UserChangesNotification) },
+	"withdrawal":                func() interface{} { return new(Withdrawal) },
+}
+
+// TestGolden decodes every response of testdata strictly, failing on fields
+// missing from the models, and checks that no decoded value is lost on encoding
+func TestGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/*/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no golden files")
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		model, ok := golden[name]
+		if !ok {
+			t.Errorf("%v: no model", file)
+			continue
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v := model()
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(v); err != nil {
+			t.Errorf("%v: %v", file, err)
+			continue
+		}
+
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			t.Errorf("%v: %v", file, err)
+			continue
+		}
+		var want, got interface{}
+		json.Unmarshal(data, &want)
+		json.Unmarshal(encoded, &got)
+		compareGolden(t, file, "", want, got)
+	}
+}
+
+// compareGolden reports the values of want missing or different in got,
+// zero values may be missing as they are omitted on encoding
+func compareGolden(t *testing.T, file string, path string, want interface{}, got interface{}) {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, _ := got.(map[string]interface{})
+		for key, value := range w {
+			if _, ok := g[key]; !ok {
+				if !isZero(value) {
+					t.Errorf("%v: %v.%v lost", file, path, key)
+				}
+				continue
+			}
+			compareGolden(t, file, path+"."+key, value, g[key])
+		}
+	case []interface{}:
+		g, _ := got.([]interface{})
+		if len(g) != len(w) {
+			t.Errorf("%v: %v has %v items, want %v", file, path, len(g), len(w))
+			return
+		}
+		for i := range w {
+			compareGolden(t, file, path+"["+strconv.Itoa(i)+"]", w[i], g[i])
+		}
+	default:
+		if !reflect.DeepEqual(want, got) && !(isZero(want) && isZero(got)) {
+			t.Errorf("%v: %v is %v, want %v", file, path, got, want)
+		}
+	}
+}
+
+func isZero(v interface{}) bool {
+	return v == nil || reflect.ValueOf(v).IsZero() || reflect.DeepEqual(v, []interface{}{})
+}
@@ -0,0 +1,9 @@
+package models
+
+type Greeks struct {
+	Delta float64 `json:"delta"`
+	Gamma float64 `json:"gamma"`
+	Rho   float64 `json:"rho"`
+	Theta float64 `json:"theta"`
+	Vega  float64 `json:"vega"`
+}
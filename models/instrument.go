@@ -1,17 +1,37 @@
 package models
 
+type TickSizeStep struct {
+	AbovePrice float64 `json:"above_price"`
+	TickSize   float64 `json:"tick_size"`
+}
+
 type Instrument struct {
-	TickSize            float64 `json:"tick_size"`
-	Strike              float64 `json:"strike"`
-	SettlementPeriod    string  `json:"settlement_period"`
-	QuoteCurrency       string  `json:"quote_currency"`
-	OptionType          string  `json:"option_type"`
-	MinTradeAmount      float64 `json:"min_trade_amount"`
-	Kind                string  `json:"kind"`
-	IsActive            bool    `json:"is_active"`
-	InstrumentName      string  `json:"instrument_name"`
-	ExpirationTimestamp int64   `json:"expiration_timestamp"`
-	CreationTimestamp   int64   `json:"creation_timestamp"`
-	ContractSize        float64 `json:"contract_size"`
-	BaseCurrency        string  `json:"base_currency"`
+	BaseCurrency             string         `json:"base_currency"`
+	BlockTradeCommission     float64        `json:"block_trade_commission"`
+	BlockTradeMinTradeAmount float64        `json:"block_trade_min_trade_amount"`
+	BlockTradeTickSize       float64        `json:"block_trade_tick_size"`
+	ContractSize             float64        `json:"contract_size"`
+	CounterCurrency          string         `json:"counter_currency"`
+	CreationTimestamp        int64          `json:"creation_timestamp"`
+	ExpirationTimestamp      int64          `json:"expiration_timestamp"`
+	FutureType               string         `json:"future_type,omitempty"`
+	InstrumentID             int            `json:"instrument_id"`
+	InstrumentName           string         `json:"instrument_name"`
+	InstrumentType           string         `json:"instrument_type"`
+	IsActive                 bool           `json:"is_active"`
+	Kind                     Kind           `json:"kind"`
+	MakerCommission          float64        `json:"maker_commission"`
+	MaxLeverage              int            `json:"max_leverage,omitempty"`
+	MaxLiquidationCommission float64        `json:"max_liquidation_commission,omitempty"`
+	MinTradeAmount           float64        `json:"min_trade_amount"`
+	OptionType               string         `json:"option_type,omitempty"`
+	PriceIndex               string         `json:"price_index"`
+	QuoteCurrency            string         `json:"quote_currency"`
+	Rfq                      bool           `json:"rfq"`
+	SettlementCurrency       string         `json:"settlement_currency"`
+	SettlementPeriod         string         `json:"settlement_period"`
+	Strike                   float64        `json:"strike,omitempty"`
+	TakerCommission          float64        `json:"taker_commission"`
+	TickSize                 float64        `json:"tick_size"`
+	TickSizeSteps            []TickSizeStep `json:"tick_size_steps"`
 }
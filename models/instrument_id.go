@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// expiryLayout is the date layout of instrument names, e.g. 27DEC24
+const expiryLayout = "2Jan06"
+
+// expiryHour is the UTC hour instruments expire at
+const expiryHour = 8
+
+// InstrumentID is the structured form of an instrument name such as
+// BTC-PERPETUAL, BTC-27DEC24, BTC-27DEC24-50000-C, XRP_USDC-30AUG24-0d625-C or BTC_USDC
+type InstrumentID struct {
+	// Currency is the base currency, e.g. BTC
+	Currency string
+	// Quote is the quote currency of linear instruments and spot pairs, e.g. USDC, empty for inverse instruments
+	Quote string
+	// Kind is KindFuture, KindOption or KindSpot
+	Kind Kind
+	// Expiry is the expiry date at 08:00 UTC, zero for perpetuals and spot pairs
+	Expiry     time.Time
+	Strike     float64
+	OptionType string
+	Perpetual  bool
+}
+
+// ParseInstrumentID parses an instrument name, combos are not supported
+func ParseInstrumentID(name string) (InstrumentID, error) {
+	var id InstrumentID
+	parts := strings.Split(name, "-")
+	id.Currency = parts[0]
+	if i := strings.IndexByte(parts[0], '_'); i >= 0 {
+		id.Currency, id.Quote = parts[0][:i], parts[0][i+1:]
+	}
+	if id.Currency == "" || len(parts) == 1 && id.Quote == "" {
+		return id, fmt.Errorf("invalid instrument name %q", name)
+	}
+
+	switch len(parts) {
+	case 1:
+		id.Kind = KindSpot
+		return id, nil
+	case 2:
+		id.Kind = KindFuture
+		if parts[1] == "PERPETUAL" {
+			id.Perpetual = true
+			return id, nil
+		}
+	case 4:
+		id.Kind = KindOption
+		strike, err := strconv.ParseFloat(strings.Replace(parts[2], "d", ".", 1), 64)
+		if err != nil || strike <= 0 {
+			return id, fmt.Errorf("invalid strike in instrument name %q", name)
+		}
+		id.Strike = strike
+		switch parts[3] {
+		case "C":
+			id.OptionType = OptionTypeCall
+		case "P":
+			id.OptionType = OptionTypePut
+		default:
+			return id, fmt.Errorf("invalid option type in instrument name %q", name)
+		}
+	default:
+		return id, fmt.Errorf("unsupported instrument name %q", name)
+	}
+
+	expiry, err := time.Parse(expiryLayout, parts[1])
+	if err != nil {
+		return id, fmt.Errorf("invalid expiry in instrument name %q", name)
+	}
+	id.Expiry = expiry.Add(expiryHour * time.Hour)
+	return id, nil
+}
+
+// Inverse reports whether the instrument is an inverse future, whose amounts are in USD
+// and whose profit and loss is settled in the base currency
+func (id InstrumentID) Inverse() bool {
+	return id.Kind == KindFuture && id.Quote == ""
+}
+
+// String formats the instrument name
+func (id InstrumentID) String() string {
+	var b strings.Builder
+	b.WriteString(id.Currency)
+	if id.Quote != "" {
+		b.WriteString("_" + id.Quote)
+	}
+	switch {
+	case id.Kind == KindSpot:
+		return b.String()
+	case id.Perpetual:
+		return b.String() + "-PERPETUAL"
+	}
+	b.WriteString("-" + strings.ToUpper(id.Expiry.UTC().Format(expiryLayout)))
+	if id.Kind == KindOption {
+		strike := strings.Replace(strconv.FormatFloat(id.Strike, 'f', -1, 64), ".", "d", 1)
+		b.WriteString("-" + strike + "-")
+		if id.OptionType == OptionTypePut {
+			b.WriteString("P")
+		} else {
+			b.WriteString("C")
+		}
+	}
+	return b.String()
+}
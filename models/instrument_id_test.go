@@ -0,0 +1,77 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseInstrumentID(t *testing.T) {
+	expiry := time.Date(2024, 12, 27, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		id   InstrumentID
+	}{
+		{"BTC-PERPETUAL", InstrumentID{Currency: "BTC", Kind: KindFuture, Perpetual: true}},
+		{"ETH_USDC-PERPETUAL", InstrumentID{Currency: "ETH", Quote: "USDC", Kind: KindFuture, Perpetual: true}},
+		{"BTC-27DEC24", InstrumentID{Currency: "BTC", Kind: KindFuture, Expiry: expiry}},
+		{"BTC-1MAY20", InstrumentID{Currency: "BTC", Kind: KindFuture, Expiry: time.Date(2020, 5, 1, 8, 0, 0, 0, time.UTC)}},
+		{"BTC-27DEC24-50000-C", InstrumentID{Currency: "BTC", Kind: KindOption, Expiry: expiry, Strike: 50000, OptionType: OptionTypeCall}},
+		{"XRP_USDC-27DEC24-0d625-P", InstrumentID{Currency: "XRP", Quote: "USDC", Kind: KindOption, Expiry: expiry, Strike: 0.625, OptionType: OptionTypePut}},
+		{"BTC_USDC", InstrumentID{Currency: "BTC", Quote: "USDC", Kind: KindSpot}},
+	}
+	for _, test := range tests {
+		id, err := ParseInstrumentID(test.name)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.id, id, test.name)
+		assert.Equal(t, test.name, id.String())
+	}
+
+	for _, name := range []string{"", "BTC", "BTC-32DEC24", "BTC-27DEC24-X-C", "BTC-27DEC24-50000-Z", "BTC-FS-27DEC24_PERP"} {
+		_, err := ParseInstrumentID(name)
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestNewOptionChain(t *testing.T) {
+	near := time.Date(2020, 5, 8, 8, 0, 0, 0, time.UTC)
+	far := time.Date(2020, 5, 29, 8, 0, 0, 0, time.UTC)
+	option := func(name string) Instrument {
+		id, _ := ParseInstrumentID(name)
+		return Instrument{
+			InstrumentName:      name,
+			Kind:                id.Kind,
+			Strike:              id.Strike,
+			OptionType:          id.OptionType,
+			ExpirationTimestamp: id.Expiry.UnixNano() / int64(time.Millisecond),
+		}
+	}
+	instruments := []Instrument{
+		option("BTC-29MAY20-9000-C"),
+		option("BTC-8MAY20-9000-P"),
+		option("BTC-8MAY20-8000-C"),
+		option("BTC-8MAY20-9000-C"),
+		option("BTC-29MAY20"),
+	}
+	summaries := []BookSummary{{InstrumentName: "BTC-8MAY20-9000-P", MarkPrice: 0.05}}
+
+	chain := NewOptionChain(instruments, summaries)
+	assert.Len(t, chain.Expiries, 2)
+	assert.Equal(t, near, chain.Expiries[0].Expiry)
+	assert.Equal(t, far, chain.Expiries[1].Expiry)
+
+	expiry, ok := chain.Expiry(near)
+	assert.True(t, ok)
+	assert.Len(t, expiry.Strikes, 2)
+	assert.Equal(t, float64(8000), expiry.Strikes[0].Strike)
+	assert.Nil(t, expiry.Strikes[0].Put)
+
+	strike, ok := expiry.Strike(9000)
+	assert.True(t, ok)
+	assert.Equal(t, "BTC-8MAY20-9000-C", strike.Call.Instrument.InstrumentName)
+	assert.Nil(t, strike.Call.Summary)
+	assert.Equal(t, 0.05, strike.Put.Summary.MarkPrice)
+
+	_, ok = expiry.Strike(8500)
+	assert.False(t, ok)
+}
@@ -0,0 +1,7 @@
+package models
+
+type InstrumentStateNotification struct {
+	InstrumentName string `json:"instrument_name"`
+	State          string `json:"state"`
+	Timestamp      int64  `json:"timestamp"`
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// OptionChain is the options of a currency grouped by expiry and strike
+type OptionChain struct {
+	Expiries []OptionExpiry `json:"expiries"`
+}
+
+// OptionExpiry is the strikes of an expiry in ascending order
+type OptionExpiry struct {
+	Expiry  time.Time      `json:"expiry"`
+	Strikes []OptionStrike `json:"strikes"`
+}
+
+// OptionStrike is the call and put of a strike, either may be nil
+type OptionStrike struct {
+	Strike float64      `json:"strike"`
+	Call   *OptionQuote `json:"call,omitempty"`
+	Put    *OptionQuote `json:"put,omitempty"`
+}
+
+// OptionQuote is an option with its book summary, nil if it has none
+type OptionQuote struct {
+	Instrument Instrument   `json:"instrument"`
+	Summary    *BookSummary `json:"summary,omitempty"`
+}
+
+// NewOptionChain builds the OptionChain of the options of instruments with their summaries,
+// other kinds are ignored
+func NewOptionChain(instruments []Instrument, summaries []BookSummary) *OptionChain {
+	byName := make(map[string]*BookSummary, len(summaries))
+	for i := range summaries {
+		byName[summaries[i].InstrumentName] = &summaries[i]
+	}
+
+	type key struct {
+		expiry int64
+		strike float64
+	}
+	strikes := make(map[key]*OptionStrike)
+	for _, instrument := range instruments {
+		if instrument.Kind != KindOption {
+			continue
+		}
+		k := key{instrument.ExpirationTimestamp, instrument.Strike}
+		strike, ok := strikes[k]
+		if !ok {
+			strike = &OptionStrike{Strike: instrument.Strike}
+			strikes[k] = strike
+		}
+		quote := &OptionQuote{Instrument: instrument, Summary: byName[instrument.InstrumentName]}
+		if instrument.OptionType == OptionTypePut {
+			strike.Put = quote
+		} else {
+			strike.Call = quote
+		}
+	}
+
+	expiries := make(map[int64][]OptionStrike)
+	for k, strike := range strikes {
+		expiries[k.expiry] = append(expiries[k.expiry], *strike)
+	}
+	chain := &OptionChain{}
+	for ts, strikes := range expiries {
+		sort.Slice(strikes, func(i, j int) bool { return strikes[i].Strike < strikes[j].Strike })
+		chain.Expiries = append(chain.Expiries, OptionExpiry{
+			Expiry:  time.Unix(0, ts*int64(time.Millisecond)).UTC(),
+			Strikes: strikes,
+		})
+	}
+	sort.Slice(chain.Expiries, func(i, j int) bool { return chain.Expiries[i].Expiry.Before(chain.Expiries[j].Expiry) })
+	return chain
+}
+
+// Expiry returns the expiry at t
+func (c *OptionChain) Expiry(t time.Time) (*OptionExpiry, bool) {
+	for i := range c.Expiries {
+		if c.Expiries[i].Expiry.Equal(t) {
+			return &c.Expiries[i], true
+		}
+	}
+	return nil, false
+}
+
+// Strike returns the call and put of strike
+func (e *OptionExpiry) Strike(strike float64) (*OptionStrike, bool) {
+	i := sort.Search(len(e.Strikes), func(i int) bool { return e.Strikes[i].Strike >= strike })
+	if i < len(e.Strikes) && e.Strikes[i].Strike == strike {
+		return &e.Strikes[i], true
+	}
+	return nil, false
+}
@@ -23,29 +23,54 @@ func (p *Price) ToFloat64() float64 {
 }
 
 type Order struct {
-	Advanced            string  `json:"advanced,omitempty"`
-	Amount              float64 `json:"amount"`
-	API                 bool    `json:"api"`
-	TimeInForce         string  `json:"time_in_force"`
-	ReduceOnly          bool    `json:"reduce_only"`
-	ProfitLoss          float64 `json:"profit_loss"`
-	Price               Price   `json:"price"`
-	PostOnly            bool    `json:"post_only"`
-	StopPrice           float64 `json:"stop_price,omitempty"`
-	Triggered           bool    `json:"triggered,omitempty"`
-	OrderType           string  `json:"order_type"`
-	OrderState          string  `json:"order_state"`
-	OrderID             string  `json:"order_id"`
-	MaxShow             float64 `json:"max_show"`
-	LastUpdateTimestamp int64   `json:"last_update_timestamp"`
-	Label               string  `json:"label"`
-	IsLiquidation       bool    `json:"is_liquidation"`
-	InstrumentName      string  `json:"instrument_name"`
-	FilledAmount        float64 `json:"filled_amount"`
-	Direction           string  `json:"direction"`
-	CreationTimestamp   int64   `json:"creation_timestamp"`
-	Commission          float64 `json:"commission"`
-	AveragePrice        float64 `json:"average_price"`
-	Implv               float64 `json:"implv,omitempty"`
-	Usd                 float64 `json:"usd,omitempty"`
+	Advanced              Advanced    `json:"advanced,omitempty"`
+	Amount                float64     `json:"amount"`
+	API                   bool        `json:"api"`
+	AppName               string      `json:"app_name,omitempty"`
+	AutoReplaced          bool        `json:"auto_replaced,omitempty"`
+	AveragePrice          float64     `json:"average_price"`
+	BlockTrade            bool        `json:"block_trade,omitempty"`
+	CancelReason          string      `json:"cancel_reason,omitempty"`
+	Commission            float64     `json:"commission"`
+	Contracts             float64     `json:"contracts,omitempty"`
+	CreationTimestamp     int64       `json:"creation_timestamp"`
+	Direction             Direction   `json:"direction"`
+	DisplayAmount         float64     `json:"display_amount,omitempty"`
+	FilledAmount          float64     `json:"filled_amount"`
+	Implv                 float64     `json:"implv,omitempty"`
+	InstrumentName        string      `json:"instrument_name"`
+	IsLiquidation         bool        `json:"is_liquidation"`
+	IsRebalance           bool        `json:"is_rebalance,omitempty"`
+	Label                 string      `json:"label"`
+	LastUpdateTimestamp   int64       `json:"last_update_timestamp"`
+	MaxShow               float64     `json:"max_show"`
+	Mmp                   bool        `json:"mmp"`
+	MmpCancelled          bool        `json:"mmp_cancelled,omitempty"`
+	MmpGroup              string      `json:"mmp_group,omitempty"`
+	Mobile                bool        `json:"mobile,omitempty"`
+	OrderID               string      `json:"order_id"`
+	OrderState            string      `json:"order_state"`
+	OrderType             OrderType   `json:"order_type"`
+	OriginalOrderType     OrderType   `json:"original_order_type,omitempty"`
+	PostOnly              bool        `json:"post_only"`
+	Price                 Price       `json:"price"`
+	ProfitLoss            float64     `json:"profit_loss"`
+	Quote                 bool        `json:"quote,omitempty"`
+	QuoteID               string      `json:"quote_id,omitempty"`
+	QuoteSetID            string      `json:"quote_set_id,omitempty"`
+	ReduceOnly            bool        `json:"reduce_only"`
+	RefreshAmount         float64     `json:"refresh_amount,omitempty"`
+	RejectPostOnly        bool        `json:"reject_post_only,omitempty"`
+	Replaced              bool        `json:"replaced"`
+	RiskReducing          bool        `json:"risk_reducing"`
+	StopPrice             float64     `json:"stop_price,omitempty"`
+	TimeInForce           TimeInForce `json:"time_in_force"`
+	Trigger               TriggerType `json:"trigger,omitempty"`
+	Triggered             bool        `json:"triggered,omitempty"`
+	TriggerOffset         float64     `json:"trigger_offset,omitempty"`
+	TriggerOrderID        string      `json:"trigger_order_id,omitempty"`
+	TriggerPrice          float64     `json:"trigger_price,omitempty"`
+	TriggerReferencePrice float64     `json:"trigger_reference_price,omitempty"`
+	Usd                   float64     `json:"usd,omitempty"`
+	Web                   bool        `json:"web"`
 }
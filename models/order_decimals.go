@@ -0,0 +1,13 @@
+package models
+
+// OrderDecimals are the numbers of an Order decoded exactly, for Client.Call results
+// such as the "order" of a BuyResponse or the orders of get_open_orders_by_currency
+type OrderDecimals struct {
+	Amount         Decimal `json:"amount"`
+	AveragePrice   Decimal `json:"average_price"`
+	Commission     Decimal `json:"commission"`
+	FilledAmount   Decimal `json:"filled_amount"`
+	InstrumentName string  `json:"instrument_name"`
+	OrderID        string  `json:"order_id"`
+	Price          Decimal `json:"price"`
+}
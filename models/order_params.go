@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+)
+
+// Validate returns an error describing the first invalid param of the buy order
+func (p *BuyParams) Validate() error {
+	return validateOrder(p)
+}
+
+// Validate returns an error describing the first invalid param of the sell order
+func (p *SellParams) Validate() error {
+	return validateOrder((*BuyParams)(p))
+}
+
+// Validate returns an error describing the first invalid param of the edit
+func (p *EditParams) Validate() error {
+	if p.OrderID == "" {
+		return fmt.Errorf("order_id is required")
+	}
+	if p.Amount <= 0 {
+		return fmt.Errorf("amount %v is not positive", p.Amount)
+	}
+	if p.RejectPostOnly && !p.PostOnly {
+		return fmt.Errorf("reject_post_only is only allowed in post_only orders")
+	}
+	if p.Advanced != "" && !p.Advanced.Valid() {
+		return enumError("advanced", string(p.Advanced), advanceds)
+	}
+	return nil
+}
+
+// Validate returns an error describing the first invalid param of closing the position
+func (p *ClosePositionParams) Validate() error {
+	if p.InstrumentName == "" {
+		return fmt.Errorf("instrument_name is required")
+	}
+	if p.Type != OrderTypeLimit && p.Type != OrderTypeMarket {
+		return enumError("type", string(p.Type), []string{string(OrderTypeLimit), string(OrderTypeMarket)})
+	}
+	if p.Type == OrderTypeLimit && p.Price <= 0 {
+		return fmt.Errorf("price is required by limit orders")
+	}
+	return nil
+}
+
+// orderTypesAccepted are the order types of new orders
+var orderTypesAccepted = []string{
+	string(OrderTypeLimit), string(OrderTypeMarket), string(OrderTypeStopLimit), string(OrderTypeStopMarket),
+	string(OrderTypeTakeLimit), string(OrderTypeTakeMarket), string(OrderTypeMarketLimit), string(OrderTypeTrailingStop),
+}
+
+// validateOrder validates the params of buy and sell orders, which have the same fields
+func validateOrder(p *BuyParams) error {
+	if p.InstrumentName == "" {
+		return fmt.Errorf("instrument_name is required")
+	}
+	if p.Amount <= 0 {
+		return fmt.Errorf("amount %v is not positive", p.Amount)
+	}
+	orderType := p.Type
+	if orderType == "" {
+		orderType = OrderTypeLimit
+	}
+	if !validEnum(string(orderType), orderTypesAccepted) {
+		return enumError("type", string(orderType), orderTypesAccepted)
+	}
+	if p.TimeInForce != "" && !p.TimeInForce.Valid() {
+		return enumError("time_in_force", string(p.TimeInForce), timeInForces)
+	}
+	if p.Trigger != "" && !p.Trigger.Valid() {
+		return enumError("trigger", string(p.Trigger), triggerTypes)
+	}
+	if p.Advanced != "" && !p.Advanced.Valid() {
+		return enumError("advanced", string(p.Advanced), advanceds)
+	}
+	limit := orderType == OrderTypeLimit || orderType == OrderTypeStopLimit || orderType == OrderTypeTakeLimit
+	stop := orderType == OrderTypeStopLimit || orderType == OrderTypeStopMarket ||
+		orderType == OrderTypeTakeLimit || orderType == OrderTypeTakeMarket || orderType == OrderTypeTrailingStop
+	if limit && p.Price <= 0 {
+		return fmt.Errorf("price is required by %v orders", orderType)
+	}
+	// trailing stops trigger at an offset from the market rather than at a stop price
+	if stop && orderType != OrderTypeTrailingStop && p.StopPrice <= 0 {
+		return fmt.Errorf("stop_price is required by %v orders", orderType)
+	}
+	if !stop && (p.StopPrice != 0 || p.Trigger != "") {
+		return fmt.Errorf("stop_price and trigger are only allowed in stop orders, not in %v orders", orderType)
+	}
+	if p.PostOnly && !limit {
+		return fmt.Errorf("post_only is only allowed in limit orders, not in %v orders", orderType)
+	}
+	if p.RejectPostOnly && !p.PostOnly {
+		return fmt.Errorf("reject_post_only is only allowed in post_only orders")
+	}
+	if p.PostOnly && p.TimeInForce != "" && p.TimeInForce != TimeInForceGoodTilCancelled {
+		return fmt.Errorf("post_only is only allowed in good_til_cancelled orders, not in %v orders", p.TimeInForce)
+	}
+	if p.MaxShow != nil && (*p.MaxShow < 0 || *p.MaxShow > p.Amount) {
+		return fmt.Errorf("max_show %v is not between 0 and the amount %v", *p.MaxShow, p.Amount)
+	}
+	if p.Advanced != "" {
+		// the kind of unparsable names is left to the exchange
+		if id, err := ParseInstrumentID(p.InstrumentName); err == nil && id.Kind != KindOption {
+			return fmt.Errorf("advanced is only allowed in option orders, not in %v orders", id.Kind)
+		}
+		if orderType != OrderTypeLimit {
+			return fmt.Errorf("advanced is only allowed in limit orders, not in %v orders", orderType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package models
+
+// OrderResponse is the response of placing an order,
+// BuyResponse and SellResponse convert to it
+type OrderResponse struct {
+	Trades []Trade `json:"trades"`
+	Order  Order   `json:"order"`
+}
@@ -1,12 +1,50 @@
 package models
 
+// Portfolio is the margin and profit and loss of a currency, the data of
+// user.portfolio notifications and the common part of AccountSummary
 type Portfolio struct {
-	AvailableFunds           int    `json:"available_funds"`
-	AvailableWithdrawalFunds int    `json:"available_withdrawal_funds"`
-	Balance                  int    `json:"balance"`
-	Currency                 string `json:"currency"`
-	Equity                   int    `json:"equity"`
-	InitialMargin            int    `json:"initial_margin"`
-	MaintenanceMargin        int    `json:"maintenance_margin"`
-	MarginBalance            int    `json:"margin_balance"`
+	AdditionalReserve            float64            `json:"additional_reserve"`
+	AvailableFunds               float64            `json:"available_funds"`
+	AvailableWithdrawalFunds     float64            `json:"available_withdrawal_funds"`
+	Balance                      float64            `json:"balance"`
+	CrossCollateralEnabled       bool               `json:"cross_collateral_enabled"`
+	Currency                     string             `json:"currency"`
+	DeltaTotal                   float64            `json:"delta_total"`
+	DeltaTotalMap                map[string]float64 `json:"delta_total_map,omitempty"`
+	Equity                       float64            `json:"equity"`
+	EstimatedLiquidationRatio    float64            `json:"estimated_liquidation_ratio"`
+	EstimatedLiquidationRatioMap map[string]float64 `json:"estimated_liquidation_ratio_map,omitempty"`
+	FeeBalance                   float64            `json:"fee_balance"`
+	FuturesPl                    float64            `json:"futures_pl"`
+	FuturesSessionRpl            float64            `json:"futures_session_rpl"`
+	FuturesSessionUpl            float64            `json:"futures_session_upl"`
+	InitialMargin                float64            `json:"initial_margin"`
+	MaintenanceMargin            float64            `json:"maintenance_margin"`
+	MarginBalance                float64            `json:"margin_balance"`
+	MarginModel                  string             `json:"margin_model,omitempty"`
+	OptionsDelta                 float64            `json:"options_delta"`
+	OptionsGamma                 float64            `json:"options_gamma"`
+	OptionsGammaMap              map[string]float64 `json:"options_gamma_map,omitempty"`
+	OptionsPl                    float64            `json:"options_pl"`
+	OptionsSessionRpl            float64            `json:"options_session_rpl"`
+	OptionsSessionUpl            float64            `json:"options_session_upl"`
+	OptionsTheta                 float64            `json:"options_theta"`
+	OptionsThetaMap              map[string]float64 `json:"options_theta_map,omitempty"`
+	OptionsValue                 float64            `json:"options_value"`
+	OptionsVega                  float64            `json:"options_vega"`
+	OptionsVegaMap               map[string]float64 `json:"options_vega_map,omitempty"`
+	PortfolioMarginingEnabled    bool               `json:"portfolio_margining_enabled"`
+	ProjectedDeltaTotal          float64            `json:"projected_delta_total"`
+	ProjectedInitialMargin       float64            `json:"projected_initial_margin"`
+	ProjectedMaintenanceMargin   float64            `json:"projected_maintenance_margin"`
+	SessionFunding               float64            `json:"session_funding"`
+	SessionRpl                   float64            `json:"session_rpl"`
+	SessionUpl                   float64            `json:"session_upl"`
+	SpotReserve                  float64            `json:"spot_reserve"`
+	TotalDeltaTotalUsd           float64            `json:"total_delta_total_usd"`
+	TotalEquityUsd               float64            `json:"total_equity_usd"`
+	TotalInitialMarginUsd        float64            `json:"total_initial_margin_usd"`
+	TotalMaintenanceMarginUsd    float64            `json:"total_maintenance_margin_usd"`
+	TotalMarginBalanceUsd        float64            `json:"total_margin_balance_usd"`
+	TotalPl                      float64            `json:"total_pl"`
 }
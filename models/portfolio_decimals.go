@@ -0,0 +1,12 @@
+package models
+
+// PortfolioDecimals are the balances of a Portfolio or an AccountSummary decoded exactly
+type PortfolioDecimals struct {
+	AvailableFunds           Decimal `json:"available_funds"`
+	AvailableWithdrawalFunds Decimal `json:"available_withdrawal_funds"`
+	Balance                  Decimal `json:"balance"`
+	Currency                 string  `json:"currency"`
+	Equity                   Decimal `json:"equity"`
+	FeeBalance               Decimal `json:"fee_balance"`
+	MarginBalance            Decimal `json:"margin_balance"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func TestPortfolioNotification_Decode(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/v" + APIVersion + "/portfolio_notification.json")
+	assert.Nil(t, err)
+
+	// notifications are decoded by jsoniter
+	var notification PortfolioNotification
+	assert.Nil(t, jsoniter.Unmarshal(data, &notification))
+	assert.Equal(t, "BTC", notification.Currency)
+	assert.Equal(t, 0.23399957, notification.Balance)
+	assert.Equal(t, 0.2340038, notification.Equity)
+	assert.Equal(t, 0.00012542, notification.InitialMargin)
+	assert.Equal(t, 0.00012542, notification.ProjectedInitialMargin)
+	assert.Equal(t, -2e-8, notification.SessionRpl)
+	assert.Equal(t, 0.0043, notification.DeltaTotal)
+
+	var decimals PortfolioDecimals
+	assert.Nil(t, jsoniter.Unmarshal(data, &decimals))
+	assert.Equal(t, "0.23399957", decimals.Balance.String())
+}
+
+func TestAccountSummary_Decode(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/v" + APIVersion + "/account_summary.json")
+	assert.Nil(t, err)
+
+	// results are decoded by encoding/json
+	var summary AccountSummary
+	assert.Nil(t, json.Unmarshal(data, &summary))
+	assert.Equal(t, "BTC", summary.Currency)
+	assert.Equal(t, 302.60065765, summary.Balance)
+	assert.Equal(t, 301.38059622, summary.AvailableFunds)
+	assert.Equal(t, map[string]float64{"btc_usd": 31.594357699}, summary.DeltaTotalMap)
+	assert.Equal(t, 10, summary.ID)
+	assert.Equal(t, "user", summary.Username)
+
+	var subaccount Subaccount
+	assert.Nil(t, json.Unmarshal([]byte(`{"portfolio":{"btc":`+string(data)+`}}`), &subaccount))
+	assert.Equal(t, 1.24074017, subaccount.Portfolio.Btc.InitialMargin)
+}
@@ -0,0 +1,11 @@
+package models
+
+// PositionDecimals are the numbers of a Position decoded exactly
+type PositionDecimals struct {
+	AveragePrice       Decimal `json:"average_price"`
+	InstrumentName     string  `json:"instrument_name"`
+	RealizedProfitLoss Decimal `json:"realized_profit_loss"`
+	Size               Decimal `json:"size"`
+	SizeCurrency       Decimal `json:"size_currency"`
+	TotalProfitLoss    Decimal `json:"total_profit_loss"`
+}
@@ -1,16 +1,19 @@
+// Code generated by cmd/gen from cmd/gen/deribit_api.json. DO NOT EDIT.
+
 package models
 
 type SellParams struct {
-	InstrumentName string   `json:"instrument_name"`
-	Amount         float64  `json:"amount"`
-	Type           string   `json:"type,omitempty"`
-	Label          string   `json:"label,omitempty"`
-	Price          float64  `json:"price,omitempty"`
-	TimeInForce    string   `json:"time_in_force,omitempty"`
-	MaxShow        *float64 `json:"max_show,omitempty"`
-	PostOnly       bool     `json:"post_only,omitempty"`
-	ReduceOnly     bool     `json:"reduce_only,omitempty"`
-	StopPrice      float64  `json:"stop_price,omitempty"`
-	Trigger        string   `json:"trigger,omitempty"`
-	Advanced       string   `json:"advanced,omitempty"`
+	InstrumentName string      `json:"instrument_name"`
+	Amount         float64     `json:"amount"`
+	Type           OrderType   `json:"type,omitempty"`
+	Label          string      `json:"label,omitempty"`
+	Price          float64     `json:"price,omitempty"`
+	TimeInForce    TimeInForce `json:"time_in_force,omitempty"`
+	MaxShow        *float64    `json:"max_show,omitempty"`
+	PostOnly       bool        `json:"post_only,omitempty"`
+	RejectPostOnly bool        `json:"reject_post_only,omitempty"`
+	ReduceOnly     bool        `json:"reduce_only,omitempty"`
+	StopPrice      float64     `json:"stop_price,omitempty"`
+	Trigger        TriggerType `json:"trigger,omitempty"`
+	Advanced       Advanced    `json:"advanced,omitempty"`
 }
@@ -9,4 +9,10 @@ type Settlement struct {
 	MarkPrice         float64 `json:"mark_price"`
 	InstrumentName    string  `json:"instrument_name"`
 	IndexPrice        float64 `json:"index_price"`
+	Funded            float64 `json:"funded,omitempty"`
+	Funding           float64 `json:"funding,omitempty"`
+	SessionBankruptcy float64 `json:"session_bankrupcy,omitempty"`
+	SessionTax        float64 `json:"session_tax,omitempty"`
+	SessionTaxRate    float64 `json:"session_tax_rate,omitempty"`
+	Socialized        float64 `json:"socialized,omitempty"`
 }
@@ -1,27 +1,40 @@
 package models
 
 type TickerStats struct {
-	Volume float64 `json:"volume"`
-	Low    float64 `json:"low"`
-	High   float64 `json:"high"`
+	High           float64 `json:"high"`
+	Low            float64 `json:"low"`
+	PriceChange    float64 `json:"price_change"`
+	Volume         float64 `json:"volume"`
+	VolumeNotional float64 `json:"volume_notional,omitempty"`
+	VolumeUsd      float64 `json:"volume_usd"`
 }
 
 type TickerResponse struct {
-	BestAskAmount   float64     `json:"best_ask_amount"`
-	BestAskPrice    float64     `json:"best_ask_price"`
-	BestBidAmount   float64     `json:"best_bid_amount"`
-	BestBidPrice    float64     `json:"best_bid_price"`
-	CurrentFunding  float64     `json:"current_funding"`
-	Funding8H       float64     `json:"funding_8h"`
-	IndexPrice      float64     `json:"index_price"`
-	InstrumentName  string      `json:"instrument_name"`
-	LastPrice       float64     `json:"last_price"`
-	MarkPrice       float64     `json:"mark_price"`
-	MaxPrice        float64     `json:"max_price"`
-	MinPrice        float64     `json:"min_price"`
-	OpenInterest    float64     `json:"open_interest"`
-	SettlementPrice float64     `json:"settlement_price"`
-	State           string      `json:"state"`
-	Stats           TickerStats `json:"stats"`
-	Timestamp       int64       `json:"timestamp"`
+	AskIv                  float64     `json:"ask_iv,omitempty"`
+	BestAskAmount          float64     `json:"best_ask_amount"`
+	BestAskPrice           float64     `json:"best_ask_price"`
+	BestBidAmount          float64     `json:"best_bid_amount"`
+	BestBidPrice           float64     `json:"best_bid_price"`
+	BidIv                  float64     `json:"bid_iv,omitempty"`
+	CurrentFunding         float64     `json:"current_funding,omitempty"`
+	DeliveryPrice          float64     `json:"delivery_price,omitempty"`
+	EstimatedDeliveryPrice float64     `json:"estimated_delivery_price"`
+	Funding8H              float64     `json:"funding_8h,omitempty"`
+	Greeks                 *Greeks     `json:"greeks,omitempty"`
+	IndexPrice             float64     `json:"index_price"`
+	InstrumentName         string      `json:"instrument_name"`
+	InterestRate           float64     `json:"interest_rate,omitempty"`
+	InterestValue          float64     `json:"interest_value,omitempty"`
+	LastPrice              float64     `json:"last_price"`
+	MarkIv                 float64     `json:"mark_iv,omitempty"`
+	MarkPrice              float64     `json:"mark_price"`
+	MaxPrice               float64     `json:"max_price"`
+	MinPrice               float64     `json:"min_price"`
+	OpenInterest           float64     `json:"open_interest"`
+	SettlementPrice        float64     `json:"settlement_price,omitempty"`
+	State                  string      `json:"state"`
+	Stats                  TickerStats `json:"stats"`
+	Timestamp              int64       `json:"timestamp"`
+	UnderlyingIndex        string      `json:"underlying_index,omitempty"`
+	UnderlyingPrice        float64     `json:"underlying_price,omitempty"`
 }
@@ -1,14 +1,21 @@
 package models
 
 type Trade struct {
-	TradeSeq       int     `json:"trade_seq"`
-	TradeID        string  `json:"trade_id"`
-	Timestamp      int64   `json:"timestamp"`
-	TickDirection  int     `json:"tick_direction"`
-	Price          float64 `json:"price"`
-	Iv             float64 `json:"iv"`
-	InstrumentName string  `json:"instrument_name"`
-	IndexPrice     float64 `json:"index_price"`
-	Direction      string  `json:"direction"`
-	Amount         float64 `json:"amount"`
+	Amount             float64   `json:"amount"`
+	BlockTradeID       string    `json:"block_trade_id,omitempty"`
+	BlockTradeLegCount int       `json:"block_trade_leg_count,omitempty"`
+	ComboID            string    `json:"combo_id,omitempty"`
+	ComboTradeID       float64   `json:"combo_trade_id,omitempty"`
+	Contracts          float64   `json:"contracts,omitempty"`
+	Direction          Direction `json:"direction"`
+	IndexPrice         float64   `json:"index_price"`
+	InstrumentName     string    `json:"instrument_name"`
+	Iv                 float64   `json:"iv,omitempty"`
+	Liquidation        string    `json:"liquidation,omitempty"`
+	MarkPrice          float64   `json:"mark_price"`
+	Price              float64   `json:"price"`
+	TickDirection      int       `json:"tick_direction"`
+	Timestamp          int64     `json:"timestamp"`
+	TradeID            string    `json:"trade_id"`
+	TradeSeq           int       `json:"trade_seq"`
 }
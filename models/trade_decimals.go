@@ -0,0 +1,11 @@
+package models
+
+// TradeDecimals are the numbers of a Trade decoded exactly
+type TradeDecimals struct {
+	Amount         Decimal `json:"amount"`
+	IndexPrice     Decimal `json:"index_price"`
+	InstrumentName string  `json:"instrument_name"`
+	Price          Decimal `json:"price"`
+	TradeID        string  `json:"trade_id"`
+	TradeSeq       int     `json:"trade_seq"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// TransferDecimals are the numbers of a Transfer decoded exactly
+type TransferDecimals struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+	ID       int     `json:"id"`
+}
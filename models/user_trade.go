@@ -1,21 +1,36 @@
 package models
 
 type UserTrade struct {
-	TradeSeq       int         `json:"trade_seq"`
-	TradeID        string      `json:"trade_id"`
-	Timestamp      int64       `json:"timestamp"`
-	TickDirection  int         `json:"tick_direction"`
-	State          string      `json:"state"`
-	SelfTrade      bool        `json:"self_trade"`
-	Price          float64     `json:"price"`
-	OrderType      string      `json:"order_type"`
-	OrderID        string      `json:"order_id"`
-	MatchingID     interface{} `json:"matching_id"`
-	Liquidity      string      `json:"liquidity"`
-	InstrumentName string      `json:"instrument_name"`
-	IndexPrice     float64     `json:"index_price"`
-	FeeCurrency    string      `json:"fee_currency"`
-	Fee            float64     `json:"fee"`
-	Direction      string      `json:"direction"`
-	Amount         float64     `json:"amount"`
+	Advanced        Advanced    `json:"advanced,omitempty"`
+	Amount          float64     `json:"amount"`
+	API             bool        `json:"api,omitempty"`
+	BlockTradeID    string      `json:"block_trade_id,omitempty"`
+	ComboID         string      `json:"combo_id,omitempty"`
+	Contracts       float64     `json:"contracts,omitempty"`
+	Direction       Direction   `json:"direction"`
+	Fee             float64     `json:"fee"`
+	FeeCurrency     string      `json:"fee_currency"`
+	IndexPrice      float64     `json:"index_price"`
+	InstrumentName  string      `json:"instrument_name"`
+	Iv              float64     `json:"iv,omitempty"`
+	Label           string      `json:"label,omitempty"`
+	Liquidation     string      `json:"liquidation,omitempty"`
+	Liquidity       string      `json:"liquidity"`
+	MarkPrice       float64     `json:"mark_price"`
+	MatchingID      interface{} `json:"matching_id"`
+	Mmp             bool        `json:"mmp,omitempty"`
+	OrderID         string      `json:"order_id"`
+	OrderType       OrderType   `json:"order_type"`
+	PostOnly        bool        `json:"post_only,omitempty"`
+	Price           float64     `json:"price"`
+	ProfitLoss      float64     `json:"profit_loss"`
+	ReduceOnly      bool        `json:"reduce_only,omitempty"`
+	RiskReducing    bool        `json:"risk_reducing,omitempty"`
+	SelfTrade       bool        `json:"self_trade"`
+	State           string      `json:"state"`
+	TickDirection   int         `json:"tick_direction"`
+	Timestamp       int64       `json:"timestamp"`
+	TradeID         string      `json:"trade_id"`
+	TradeSeq        int         `json:"trade_seq"`
+	UnderlyingPrice float64     `json:"underlying_price,omitempty"`
 }
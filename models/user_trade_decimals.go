@@ -0,0 +1,15 @@
+package models
+
+// UserTradeDecimals are the numbers of a UserTrade decoded exactly
+type UserTradeDecimals struct {
+	Amount         Decimal `json:"amount"`
+	Fee            Decimal `json:"fee"`
+	FeeCurrency    string  `json:"fee_currency"`
+	IndexPrice     Decimal `json:"index_price"`
+	InstrumentName string  `json:"instrument_name"`
+	OrderID        string  `json:"order_id"`
+	Price          Decimal `json:"price"`
+	ProfitLoss     Decimal `json:"profit_loss"`
+	TradeID        string  `json:"trade_id"`
+	TradeSeq       int     `json:"trade_seq"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// WithdrawalDecimals are the numbers of a Withdrawal decoded exactly
+type WithdrawalDecimals struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+	Fee      Decimal `json:"fee"`
+	ID       int     `json:"id"`
+}
@@ -0,0 +1,415 @@
+package oms
+
+import (
+	"context"
+	"github.com/chuckpreslar/emission"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/models"
+	"sync"
+)
+
+// syncMaxInFlight is the number of concurrent order state calls of Sync
+const syncMaxInFlight = 5
+
+// Manager tracks the state of the orders of an account from the responses of the
+// calls it makes and from the user.orders and user.trades channels.
+//
+// Notifications are applied in LastUpdateTimestamp order, older ones are ignored,
+// and trades received before their order are kept until it is known.
+// Listeners run on the notification goroutine and must not call the Client.
+type Manager struct {
+	client     *deribit.Client
+	placer     *deribit.OrderPlacer
+	currencies []string
+	emitter    *emission.Emitter
+
+	mu        sync.Mutex
+	all       []*Order          // in the order they were first seen
+	orders    map[string]*Order // by order id
+	pending   map[string]*Order // pending new, by label
+	unmatched map[string][]models.UserTrade
+	running   bool
+	listening bool
+}
+
+// NewManager creates a Manager of the orders of currencies, all currencies if none
+func NewManager(client *deribit.Client, currencies ...string) *Manager {
+	return &Manager{
+		client:     client,
+		placer:     deribit.NewOrderPlacer(client, "oms"),
+		currencies: currencies,
+		emitter:    emission.NewEmitter(),
+		orders:     make(map[string]*Order),
+		pending:    make(map[string]*Order),
+		unmatched:  make(map[string][]models.UserTrade),
+	}
+}
+
+// On adds a listener func(*Transition) for EventTransition or func(*Fill) for EventFill
+func (m *Manager) On(event interface{}, listener interface{}) *emission.Emitter {
+	return m.emitter.On(event, listener)
+}
+
+// Off removes a listener
+func (m *Manager) Off(event interface{}, listener interface{}) *emission.Emitter {
+	return m.emitter.Off(event, listener)
+}
+
+// Placer returns the OrderPlacer of Place, e.g. to change its timeouts
+func (m *Manager) Placer() *deribit.OrderPlacer {
+	return m.placer
+}
+
+// Start subscribes to the order and trade notifications of the currencies until Stop
+func (m *Manager) Start() {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = true
+	listening := m.listening
+	m.listening = true
+	m.mu.Unlock()
+
+	if listening {
+		return
+	}
+	currencies := m.currencies
+	if len(currencies) == 0 {
+		currencies = []string{"any"}
+	}
+	var channels []string
+	for _, currency := range currencies {
+		orders := "user.orders.any." + currency + ".raw"
+		trades := "user.trades.any." + currency + ".raw"
+		// emission removes listeners by code pointer, so the listeners stay
+		// registered and ignore notifications while stopped
+		m.client.On(orders, m.onOrders)
+		m.client.On(trades, m.onTrades)
+		channels = append(channels, orders, trades)
+	}
+	m.client.Subscribe(channels)
+}
+
+// Stop stops applying notifications
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.running = false
+}
+
+// Sync loads the open orders of the currencies and the state of the known open orders
+// which are not open anymore, e.g. filled or cancelled while disconnected.
+// Call it after a restart or a reconnect.
+func (m *Manager) Sync() error {
+	currencies := m.currencies
+	if len(currencies) == 0 {
+		all, err := m.client.GetCurrencies()
+		if err != nil {
+			return err
+		}
+		for _, currency := range all {
+			currencies = append(currencies, currency.Currency)
+		}
+	}
+	open := make(map[string]bool)
+	for _, currency := range currencies {
+		orders, err := m.client.GetOpenOrdersByCurrency(&models.GetOpenOrdersByCurrencyParams{Currency: currency})
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			open[order.OrderID] = true
+			m.apply(order)
+		}
+	}
+
+	closed := m.missing(open)
+	if len(closed) == 0 {
+		return nil
+	}
+	orders, errs := m.client.GetOrderStates(closed, syncMaxInFlight)
+	var firstErr error
+	for i, order := range orders {
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		m.apply(order)
+	}
+	return firstErr
+}
+
+// missing returns the ids of the acknowledged orders which are neither terminal nor in open,
+// whose updates were missed
+func (m *Manager) missing(open map[string]bool) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var ids []string
+	for _, order := range m.all {
+		if order.OrderID == "" || order.State.Terminal() || open[order.OrderID] {
+			continue
+		}
+		ids = append(ids, order.OrderID)
+	}
+	return ids
+}
+
+// Place places req at most once with the OrderPlacer, tracking it as pending new until
+// it is acknowledged. The order is labelled unless req has a label, req itself is
+// unchanged. Orders whose placement is unconfirmed stay pending new until a
+// notification of their label arrives.
+func (m *Manager) Place(ctx context.Context, req *deribit.OrderRequest) (Order, error) {
+	if req.Params().Label == "" {
+		req = req.Label(m.placer.NewLabel())
+	}
+	if err := req.Validate(); err != nil {
+		return Order{}, err
+	}
+	params := req.Params()
+	pending := &Order{
+		Order: models.Order{
+			InstrumentName: params.InstrumentName,
+			Label:          params.Label,
+			Direction:      req.Direction(),
+			Amount:         params.Amount,
+			Price:          models.Price(params.Price),
+			OrderType:      params.Type,
+			StopPrice:      params.StopPrice,
+			PostOnly:       params.PostOnly,
+			ReduceOnly:     params.ReduceOnly,
+		},
+		State: StatePendingNew,
+	}
+	m.mu.Lock()
+	m.pending[params.Label] = pending
+	m.all = append(m.all, pending)
+	transition := &Transition{To: StatePendingNew, Order: pending.copy()}
+	m.mu.Unlock()
+	m.emit([]*Transition{transition}, nil)
+
+	order, err := m.placer.Place(ctx, req)
+	if err == nil {
+		m.apply(order)
+		current, _ := m.Order(order.OrderID)
+		return current, nil
+	}
+	if err != deribit.ErrOrderUnconfirmed {
+		m.reject(params.Label, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return pending.copy(), err
+}
+
+// Edit edits an order and applies the response
+func (m *Manager) Edit(params *models.EditParams) (Order, error) {
+	result, err := m.client.Edit(params)
+	if err != nil {
+		return Order{}, err
+	}
+	m.apply(result.Order)
+	order, _ := m.Order(result.Order.OrderID)
+	return order, nil
+}
+
+// Cancel cancels an order and applies the response
+func (m *Manager) Cancel(orderID string) (Order, error) {
+	result, err := m.client.Cancel(&models.CancelParams{OrderID: orderID})
+	if err != nil {
+		return Order{}, err
+	}
+	m.apply(result)
+	order, _ := m.Order(orderID)
+	return order, nil
+}
+
+// Order returns the order of orderID
+func (m *Manager) Order(orderID string) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, ok := m.orders[orderID]
+	if !ok {
+		return Order{}, false
+	}
+	return order.copy(), true
+}
+
+// ByLabel returns the orders labelled label, including pending new ones
+func (m *Manager) ByLabel(label string) []Order {
+	return m.filter(func(o *Order) bool {
+		return o.Label == label
+	})
+}
+
+// ByInstrument returns the orders of instrumentName
+func (m *Manager) ByInstrument(instrumentName string) []Order {
+	return m.filter(func(o *Order) bool {
+		return o.InstrumentName == instrumentName
+	})
+}
+
+// Open returns the orders which are not in a terminal state, including pending new ones
+func (m *Manager) Open() []Order {
+	return m.filter(func(o *Order) bool {
+		return !o.State.Terminal()
+	})
+}
+
+// Orders returns every order in the order they were first seen
+func (m *Manager) Orders() []Order {
+	return m.filter(func(o *Order) bool {
+		return true
+	})
+}
+
+func (m *Manager) filter(match func(*Order) bool) []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []Order
+	for _, order := range m.all {
+		if match(order) {
+			result = append(result, order.copy())
+		}
+	}
+	return result
+}
+
+func (m *Manager) onOrders(e *models.UserOrderNotification) {
+	if !m.isRunning() {
+		return
+	}
+	for _, order := range *e {
+		m.apply(order)
+	}
+}
+
+func (m *Manager) onTrades(e *models.UserTradesNotification) {
+	if !m.isRunning() {
+		return
+	}
+	for _, trade := range *e {
+		m.mu.Lock()
+		transitions, fills := m.addTrades(trade.OrderID, []models.UserTrade{trade})
+		m.mu.Unlock()
+		m.emit(transitions, fills)
+	}
+}
+
+func (m *Manager) isRunning() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.running
+}
+
+// apply updates the order of update unless it is older than the known state
+func (m *Manager) apply(update models.Order) {
+	m.mu.Lock()
+	order, ok := m.orders[update.OrderID]
+	if !ok {
+		if pending, found := m.pending[update.Label]; found && update.Label != "" {
+			order = pending
+			delete(m.pending, update.Label)
+		} else {
+			order = &Order{}
+			m.all = append(m.all, order)
+		}
+		m.orders[update.OrderID] = order
+	}
+	var transitions []*Transition
+	var fills []*Fill
+	if order.supersedes(&update) {
+		from := order.State
+		order.Order = update
+		order.State = stateOf(&update)
+		if order.State != from {
+			transitions = append(transitions, &Transition{From: from, To: order.State, Order: order.copy()})
+		}
+	}
+	if trades, ok := m.unmatched[update.OrderID]; ok {
+		delete(m.unmatched, update.OrderID)
+		t, f := m.addTrades(update.OrderID, trades)
+		transitions, fills = append(transitions, t...), append(fills, f...)
+	}
+	m.mu.Unlock()
+	m.emit(transitions, fills)
+}
+
+// addTrades adds the new trades of orderID, filling the order ahead of its notifications,
+// or keeps them until the order is known
+func (m *Manager) addTrades(orderID string, trades []models.UserTrade) ([]*Transition, []*Fill) {
+	order, ok := m.orders[orderID]
+	if !ok {
+		m.unmatched[orderID] = append(m.unmatched[orderID], trades...)
+		return nil, nil
+	}
+	var transitions []*Transition
+	var fills []*Fill
+	for _, trade := range trades {
+		if hasTrade(order.Trades, trade.TradeID) {
+			continue
+		}
+		order.Trades = append(order.Trades, trade)
+		filled := 0.0
+		for _, t := range order.Trades {
+			filled += t.Amount
+		}
+		from := order.State
+		if filled > order.FilledAmount {
+			order.FilledAmount = filled
+			if filled >= order.Amount {
+				order.State = StateFilled
+			} else if !from.Terminal() {
+				order.State = StatePartiallyFilled
+			}
+		}
+		fills = append(fills, &Fill{Trade: trade, Order: order.copy()})
+		if order.State != from {
+			transitions = append(transitions, &Transition{From: from, To: order.State, Order: order.copy()})
+		}
+	}
+	return transitions, fills
+}
+
+// reject marks the pending order of label rejected by err
+func (m *Manager) reject(label string, err error) {
+	m.mu.Lock()
+	order, ok := m.pending[label]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.pending, label)
+	order.State = StateRejected
+	order.OrderState = models.OrderStateRejected
+	order.Err = err
+	transition := &Transition{From: StatePendingNew, To: StateRejected, Order: order.copy()}
+	m.mu.Unlock()
+	m.emit([]*Transition{transition}, nil)
+}
+
+func (m *Manager) emit(transitions []*Transition, fills []*Fill) {
+	for _, t := range transitions {
+		m.emitter.Emit(EventTransition, t)
+	}
+	for _, f := range fills {
+		m.emitter.Emit(EventFill, f)
+	}
+}
+
+func hasTrade(trades []models.UserTrade, tradeID string) bool {
+	for _, t := range trades {
+		if t.TradeID == tradeID {
+			return true
+		}
+	}
+	return false
+}
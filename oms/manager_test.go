@@ -0,0 +1,175 @@
+package oms
+
+import (
+	"context"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newClient(server *deribittest.Server) *deribit.Client {
+	return deribit.New(&deribit.Configuration{
+		Addr:      server.URL,
+		ApiKey:    "key",
+		SecretKey: "secret",
+	})
+}
+
+func TestManager_Lifecycle(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	client := newClient(server)
+
+	manager := NewManager(client)
+	var mu sync.Mutex
+	states := make(map[string][]State)
+	var fills []*Fill
+	manager.On(EventTransition, func(e *Transition) {
+		mu.Lock()
+		defer mu.Unlock()
+		states[e.Order.Label] = append(states[e.Order.Label], e.To)
+	})
+	manager.On(EventFill, func(e *Fill) {
+		mu.Lock()
+		defer mu.Unlock()
+		fills = append(fills, e)
+	})
+	manager.Start()
+	defer manager.Stop()
+	assert.True(t, server.WaitSubscribed("user.orders.any.any.raw", time.Second))
+
+	order, err := manager.Place(context.Background(), deribit.Limit("BTC-PERPETUAL").Buy(10).At(6000).Label("bid"))
+	assert.Nil(t, err)
+	assert.Equal(t, StateOpen, order.State)
+
+	_, err = client.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 4, Price: 6000})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		order, _ := manager.Order(order.OrderID)
+		return order.State == StatePartiallyFilled && len(order.Trades) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	cancelled, err := manager.Cancel(order.OrderID)
+	assert.Nil(t, err)
+	assert.Equal(t, StateCancelled, cancelled.State)
+	assert.Equal(t, 4.0, cancelled.FilledAmount)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(states[""]) > 0 && len(fills) == 2
+	}, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, []State{StatePendingNew, StateOpen, StatePartiallyFilled, StateCancelled}, states["bid"])
+	assert.Equal(t, []State{StateFilled}, states[""], "orders of other clients are tracked from notifications")
+	mu.Unlock()
+
+	assert.Len(t, manager.ByLabel("bid"), 1)
+	assert.Len(t, manager.ByInstrument("BTC-PERPETUAL"), 2)
+	assert.Empty(t, manager.Open())
+}
+
+func TestManager_Sync(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	client := deribit.New(&deribit.Configuration{
+		Addr:          server.URL,
+		ApiKey:        "key",
+		SecretKey:     "secret",
+		AutoReconnect: true,
+	})
+
+	manager := NewManager(client, "BTC")
+	var mu sync.Mutex
+	var states []State
+	manager.On(EventTransition, func(e *Transition) {
+		mu.Lock()
+		defer mu.Unlock()
+		states = append(states, e.To)
+	})
+	manager.Start()
+	defer manager.Stop()
+	assert.True(t, server.WaitSubscribed("user.orders.any.BTC.raw", time.Second))
+	bid, err := manager.Place(context.Background(), deribit.Limit("BTC-PERPETUAL").Buy(10).At(6000).Label("bid"))
+	assert.Nil(t, err)
+	ask, err := manager.Place(context.Background(), deribit.Limit("BTC-PERPETUAL").Sell(10).At(6500).Label("ask"))
+	assert.Nil(t, err)
+
+	// the bid fills while the client reconnects, its notifications are lost
+	server.DisconnectAll()
+	assert.Eventually(t, func() bool {
+		return !client.IsConnected()
+	}, time.Second, 10*time.Millisecond)
+	other := newClient(server)
+	_, err = other.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6000})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		return client.IsConnected()
+	}, 5*time.Second, 10*time.Millisecond)
+	order, _ := manager.Order(bid.OrderID)
+	assert.Equal(t, StateOpen, order.State)
+
+	assert.Nil(t, manager.Sync())
+	order, _ = manager.Order(bid.OrderID)
+	assert.Equal(t, StateFilled, order.State)
+	assert.Equal(t, 10.0, order.FilledAmount)
+	order, _ = manager.Order(ask.OrderID)
+	assert.Equal(t, StateOpen, order.State)
+	assert.Equal(t, 1, server.CallCount("private/get_order_state"), "only orders missing from the open orders are fetched")
+	mu.Lock()
+	assert.Equal(t, StateFilled, states[len(states)-1])
+	mu.Unlock()
+}
+
+func TestManager_Rejected(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.RespondError("private/buy", 10009, "not_enough_funds")
+	client := newClient(server)
+
+	manager := NewManager(client)
+	order, err := manager.Place(context.Background(), deribit.Limit("BTC-PERPETUAL").Buy(10).At(6000))
+	assert.NotNil(t, err)
+	assert.Equal(t, StateRejected, order.State)
+	assert.Equal(t, err, order.Err)
+	assert.Empty(t, manager.Open())
+	assert.Len(t, manager.ByLabel(order.Label), 1)
+}
+
+func TestManager_OutOfOrder(t *testing.T) {
+	manager := NewManager(nil)
+	var transitions []*Transition
+	manager.On(EventTransition, func(e *Transition) {
+		transitions = append(transitions, e)
+	})
+
+	open := models.Order{OrderID: "1", Amount: 10, OrderState: models.OrderStateOpen, LastUpdateTimestamp: 1}
+	partial := models.Order{OrderID: "1", Amount: 10, FilledAmount: 4, OrderState: models.OrderStateOpen, LastUpdateTimestamp: 2}
+	filled := models.Order{OrderID: "1", Amount: 10, FilledAmount: 10, OrderState: models.OrderStateFilled, LastUpdateTimestamp: 2}
+
+	// the trade arrives twice before the order, then the updates out of order
+	manager.running = true
+	manager.onTrades(&models.UserTradesNotification{{TradeID: "t1", OrderID: "1", Amount: 4}})
+	manager.onTrades(&models.UserTradesNotification{{TradeID: "t1", OrderID: "1", Amount: 4}})
+	manager.apply(partial)
+	manager.apply(open)
+	order, _ := manager.Order("1")
+	assert.Equal(t, StatePartiallyFilled, order.State)
+	assert.Equal(t, int64(2), order.LastUpdateTimestamp)
+	assert.Len(t, order.Trades, 1)
+
+	manager.apply(filled)
+	manager.apply(partial)
+	order, _ = manager.Order("1")
+	assert.Equal(t, StateFilled, order.State, "same millisecond updates do not leave terminal states")
+
+	var states []State
+	for _, e := range transitions {
+		states = append(states, e.To)
+	}
+	assert.Equal(t, []State{StatePartiallyFilled, StateFilled}, states)
+}
@@ -0,0 +1,103 @@
+package oms
+
+import (
+	"github.com/frankrap/deribit-api/models"
+)
+
+// State is the state of an order tracked by a Manager
+type State string
+
+const (
+	// StatePendingNew orders were sent but not acknowledged yet
+	StatePendingNew State = "pending_new"
+	// StateOpen orders rest in the book without fills
+	StateOpen State = "open"
+	// StatePartiallyFilled orders rest in the book with some of their amount filled
+	StatePartiallyFilled State = "partially_filled"
+	// StateFilled orders are completely filled
+	StateFilled State = "filled"
+	// StateCancelled orders were cancelled, possibly after partial fills
+	StateCancelled State = "cancelled"
+	// StateRejected orders were refused by the exchange
+	StateRejected State = "rejected"
+	// StateUntriggered stop orders wait for their trigger
+	StateUntriggered State = "untriggered"
+)
+
+// Terminal reports whether s is final, orders never leave terminal states
+func (s State) Terminal() bool {
+	return s == StateFilled || s == StateCancelled || s == StateRejected
+}
+
+// Order is an order tracked by a Manager
+type Order struct {
+	// Order is the last state reported by the exchange, its OrderID is empty while pending new
+	models.Order
+	State State
+	// Trades are the fills of the order in the order they were received
+	Trades []models.UserTrade
+	// Err is the error of rejected orders
+	Err error
+}
+
+// Events emitted by a Manager
+const (
+	// EventTransition is emitted with a *Transition when an order changes state
+	EventTransition = "transition"
+	// EventFill is emitted with a *Fill for every new trade of an order
+	EventFill = "fill"
+)
+
+// Transition is a state change of an order
+type Transition struct {
+	From  State
+	To    State
+	Order Order
+}
+
+// Fill is a trade of an order
+type Fill struct {
+	Trade models.UserTrade
+	Order Order
+}
+
+// stateOf returns the state of order as reported by the exchange
+func stateOf(order *models.Order) State {
+	switch order.OrderState {
+	case models.OrderStateFilled:
+		return StateFilled
+	case models.OrderStateCancelled:
+		return StateCancelled
+	case models.OrderStateRejected:
+		return StateRejected
+	case models.OrderStateUntriggered:
+		return StateUntriggered
+	}
+	if order.FilledAmount > 0 {
+		return StatePartiallyFilled
+	}
+	return StateOpen
+}
+
+// supersedes reports whether update is newer than the known state of o.
+// Updates are ordered by LastUpdateTimestamp; updates of the same millisecond
+// are accepted unless they would undo fills or leave a terminal state.
+func (o *Order) supersedes(update *models.Order) bool {
+	if o.OrderID == "" {
+		return true
+	}
+	if update.LastUpdateTimestamp != o.LastUpdateTimestamp {
+		return update.LastUpdateTimestamp > o.LastUpdateTimestamp
+	}
+	if update.FilledAmount < o.FilledAmount {
+		return false
+	}
+	return !o.State.Terminal() || stateOf(update) == o.State
+}
+
+// copy returns a copy of o which does not share its trades
+func (o *Order) copy() Order {
+	c := *o
+	c.Trades = append([]models.UserTrade(nil), o.Trades...)
+	return c
+}
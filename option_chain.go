@@ -0,0 +1,18 @@
+package deribit
+
+import (
+	"github.com/frankrap/deribit-api/models"
+)
+
+// GetOptionChain returns the option chain of currency with the book summaries of its options
+func (c *Client) GetOptionChain(currency string) (*models.OptionChain, error) {
+	instruments, err := c.GetInstruments(&models.GetInstrumentsParams{Currency: currency, Kind: models.KindOption})
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := c.GetBookSummaryByCurrency(&models.GetBookSummaryByCurrencyParams{Currency: currency, Kind: models.KindOption})
+	if err != nil {
+		return nil, err
+	}
+	return models.NewOptionChain(instruments, summaries), nil
+}
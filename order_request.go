@@ -0,0 +1,183 @@
+package deribit
+
+import (
+	"context"
+	"fmt"
+	"github.com/frankrap/deribit-api/models"
+)
+
+// OrderRequest builds an order, e.g.
+//
+//	Limit("BTC-PERPETUAL").Buy(100).At(60000).PostOnly().Label("mm")
+//
+// and is placed by PlaceOrder. Invalid combinations are reported by Validate
+// and PlaceOrder before the order is sent. Setters return a new request and
+// leave their receiver unchanged, so that a request can serve as a template:
+//
+//	quote := Limit("BTC-PERPETUAL").PostOnly()
+//	bid, ask := quote.Buy(100).At(59990), quote.Sell(100).At(60010)
+type OrderRequest struct {
+	direction models.Direction
+	params    models.BuyParams
+}
+
+func newOrderRequest(instrumentName string, orderType models.OrderType) *OrderRequest {
+	return &OrderRequest{params: models.BuyParams{InstrumentName: instrumentName, Type: orderType}}
+}
+
+// Limit starts a limit order of instrumentName, its price is set by At
+func Limit(instrumentName string) *OrderRequest {
+	return newOrderRequest(instrumentName, models.OrderTypeLimit)
+}
+
+// Market starts a market order of instrumentName
+func Market(instrumentName string) *OrderRequest {
+	return newOrderRequest(instrumentName, models.OrderTypeMarket)
+}
+
+// StopLimit starts a stop limit order of instrumentName, its stop price is set by StopAt
+// and its limit price by At
+func StopLimit(instrumentName string) *OrderRequest {
+	return newOrderRequest(instrumentName, models.OrderTypeStopLimit)
+}
+
+// StopMarket starts a stop market order of instrumentName, its stop price is set by StopAt
+func StopMarket(instrumentName string) *OrderRequest {
+	return newOrderRequest(instrumentName, models.OrderTypeStopMarket)
+}
+
+// clone returns a copy of r for a setter to change
+func (r *OrderRequest) clone() *OrderRequest {
+	c := *r
+	c.params = r.Params()
+	return &c
+}
+
+// Buy buys amount, in USD for futures and in the base currency for options
+func (r *OrderRequest) Buy(amount float64) *OrderRequest {
+	c := r.clone()
+	c.direction = models.DirectionBuy
+	c.params.Amount = amount
+	return c
+}
+
+// Sell sells amount, in USD for futures and in the base currency for options
+func (r *OrderRequest) Sell(amount float64) *OrderRequest {
+	c := r.clone()
+	c.direction = models.DirectionSell
+	c.params.Amount = amount
+	return c
+}
+
+// At sets the limit price, in USD or implied volatility for advanced option orders
+func (r *OrderRequest) At(price float64) *OrderRequest {
+	c := r.clone()
+	c.params.Price = price
+	return c
+}
+
+// StopAt sets the price triggering a stop order
+func (r *OrderRequest) StopAt(stopPrice float64) *OrderRequest {
+	c := r.clone()
+	c.params.StopPrice = stopPrice
+	return c
+}
+
+// Trigger sets the price type compared with the stop price, the last price by default
+func (r *OrderRequest) Trigger(trigger models.TriggerType) *OrderRequest {
+	c := r.clone()
+	c.params.Trigger = trigger
+	return c
+}
+
+// TimeInForce sets the time in force, good til cancelled by default
+func (r *OrderRequest) TimeInForce(timeInForce models.TimeInForce) *OrderRequest {
+	c := r.clone()
+	c.params.TimeInForce = timeInForce
+	return c
+}
+
+// PostOnly makes a limit order maker only
+func (r *OrderRequest) PostOnly() *OrderRequest {
+	c := r.clone()
+	c.params.PostOnly = true
+	return c
+}
+
+// ReduceOnly makes the order only reduce the position
+func (r *OrderRequest) ReduceOnly() *OrderRequest {
+	c := r.clone()
+	c.params.ReduceOnly = true
+	return c
+}
+
+// Iceberg shows at most maxShow of the amount in the book, 0 hides the order
+func (r *OrderRequest) Iceberg(maxShow float64) *OrderRequest {
+	c := r.clone()
+	c.params.MaxShow = &maxShow
+	return c
+}
+
+// USD makes an option limit order an advanced order priced in USD
+func (r *OrderRequest) USD() *OrderRequest {
+	c := r.clone()
+	c.params.Advanced = models.AdvancedUSD
+	return c
+}
+
+// ImplV makes an option limit order an advanced order priced in implied volatility
+func (r *OrderRequest) ImplV() *OrderRequest {
+	c := r.clone()
+	c.params.Advanced = models.AdvancedImplV
+	return c
+}
+
+// Label sets the user defined label of the order
+func (r *OrderRequest) Label(label string) *OrderRequest {
+	c := r.clone()
+	c.params.Label = label
+	return c
+}
+
+// Direction returns the direction of the order, empty until Buy or Sell
+func (r *OrderRequest) Direction() models.Direction {
+	return r.direction
+}
+
+// Params returns a copy of the params of the order, which convert to models.SellParams for sells
+func (r *OrderRequest) Params() models.BuyParams {
+	params := r.params
+	if params.MaxShow != nil {
+		maxShow := *params.MaxShow
+		params.MaxShow = &maxShow
+	}
+	return params
+}
+
+// Validate returns an error describing the first invalid param or combination of params
+func (r *OrderRequest) Validate() error {
+	if r.direction != models.DirectionBuy && r.direction != models.DirectionSell {
+		return fmt.Errorf("direction is required, call Buy or Sell")
+	}
+	return r.params.Validate()
+}
+
+// PlaceOrder validates req and places it with Buy or Sell depending on its direction
+func (c *Client) PlaceOrder(req *OrderRequest) (result models.OrderResponse, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+	return c.placeOrder(c.ctx, req)
+}
+
+// placeOrder sends req, which must be valid, to private/buy or private/sell
+func (c *Client) placeOrder(ctx context.Context, req *OrderRequest) (result models.OrderResponse, err error) {
+	params := req.Params()
+	if req.direction == models.DirectionBuy {
+		err = c.CallContext(ctx, "private/buy", &params, &result)
+		return
+	}
+	sellParams := models.SellParams(params)
+	err = c.CallContext(ctx, "private/sell", &sellParams, &result)
+	return
+}
@@ -0,0 +1,55 @@
+package deribit
+
+import (
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOrderRequest_Validate(t *testing.T) {
+	assert.Nil(t, Limit("BTC-PERPETUAL").Buy(100).At(60000).PostOnly().Label("mm").Validate())
+	assert.Nil(t, StopMarket("BTC-PERPETUAL").Sell(100).StopAt(55000).Trigger(models.TriggerTypeMarkPrice).ReduceOnly().Validate())
+	assert.Nil(t, Limit("BTC-29MAY20-6000-C").Sell(1).At(80).ImplV().Validate())
+
+	assert.EqualError(t, Limit("BTC-PERPETUAL").At(60000).Validate(), "direction is required, call Buy or Sell")
+	assert.EqualError(t, StopLimit("BTC-PERPETUAL").Buy(100).At(60000).Validate(), "stop_price is required by stop_limit orders")
+	assert.EqualError(t, Limit("BTC-PERPETUAL").Buy(100).At(60000).USD().Validate(), "advanced is only allowed in option orders, not in future orders")
+	assert.EqualError(t, Market("BTC-29MAY20-6000-C").Buy(1).ImplV().Validate(), "advanced is only allowed in limit orders, not in market orders")
+	assert.EqualError(t, Limit("BTC-PERPETUAL").Buy(100).At(60000).Iceberg(200).Validate(), "max_show 200 is not between 0 and the amount 100")
+	assert.EqualError(t, Limit("BTC-PERPETUAL").Buy(100).At(60000).PostOnly().TimeInForce(models.TimeInForceImmediateOrCancel).Validate(),
+		"post_only is only allowed in good_til_cancelled orders, not in immediate_or_cancel orders")
+
+	quote := Limit("BTC-PERPETUAL").PostOnly().Iceberg(10)
+	bid, ask := quote.Buy(100).At(59990), quote.Sell(50).At(60010)
+	assert.Equal(t, models.Direction(""), quote.Direction(), "setters leave their receiver unchanged")
+	assert.Equal(t, 0.0, quote.Params().Price)
+	assert.Equal(t, models.DirectionBuy, bid.Direction())
+	assert.Equal(t, 59990.0, bid.Params().Price)
+	assert.Equal(t, 50.0, ask.Params().Amount)
+	assert.True(t, ask.Params().PostOnly)
+	bid.Iceberg(20)
+	assert.Equal(t, 10.0, *bid.Params().MaxShow)
+}
+
+func TestMock_PlaceOrder(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	client := newMockClient(server)
+	buy, err := client.PlaceOrder(Limit("BTC-PERPETUAL").Buy(100).At(6000).PostOnly().Iceberg(50).Label("mm"))
+	assert.Nil(t, err)
+	assert.Equal(t, models.DirectionBuy, buy.Order.Direction)
+	assert.Equal(t, "mm", buy.Order.Label)
+	assert.Equal(t, 50.0, buy.Order.MaxShow)
+
+	sell, err := client.PlaceOrder(Limit("BTC-PERPETUAL").Sell(100).At(6500))
+	assert.Nil(t, err)
+	assert.Equal(t, models.DirectionSell, sell.Order.Direction)
+	assert.Equal(t, 6500.0, sell.Order.Price.ToFloat64())
+
+	_, err = client.PlaceOrder(Market("BTC-PERPETUAL").Buy(100).PostOnly())
+	assert.NotNil(t, err)
+	_, ok := server.Order("3")
+	assert.False(t, ok, "invalid orders are not sent")
+}
@@ -17,3 +17,8 @@ type Token struct {
 func (t *Token) setToken(token string) {
 	t.AccessToken = token
 }
+
+// validator is interface for params validated before they are sent
+type validator interface {
+	Validate() error
+}
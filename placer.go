@@ -0,0 +1,208 @@
+package deribit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/sourcegraph/jsonrpc2"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultPlaceTimeout bounds every lookup call of an OrderPlacer
+	DefaultPlaceTimeout = 5 * time.Second
+	// DefaultReconcileDelay is the wait before looking up an order whose placement is unknown
+	DefaultReconcileDelay = time.Second
+	// DefaultReconcileTimeout bounds the search for an order whose placement is unknown
+	DefaultReconcileTimeout = 30 * time.Second
+	// DefaultPlaceAttempts is the number of times an OrderPlacer sends an order which was not placed
+	DefaultPlaceAttempts = 3
+
+	// reconcilePageSize is the count of orders per page of order history searched for a label
+	reconcilePageSize = 100
+	// reconcileClockSkew is the margin of the order history searched before the first
+	// placement, for differences between the local and the exchange clocks
+	reconcileClockSkew = time.Minute
+)
+
+// ErrOrderUnconfirmed is returned by OrderPlacer.Place when it cannot know whether the order
+// was placed: its context ended or the search for the order failed. The order may still be
+// found by its label
+var ErrOrderUnconfirmed = errors.New("order placement unconfirmed")
+
+// OrderPlacer places orders at most once, identified by unique labels.
+// A placement waits for its response, however late, unless its connection drops; the
+// open orders and the order history of the instrument since the placement are then
+// searched for the label, and the order is sent again only when it is not found.
+// Labels must not be reused by other orders of the instrument.
+type OrderPlacer struct {
+	// Timeout bounds every lookup call, defaults to DefaultPlaceTimeout.
+	// Placements are only bounded by the context of Place.
+	Timeout time.Duration
+	// ReconcileDelay is the wait before searching for an order whose placement is unknown,
+	// so that a lost request still reaching the exchange is executed first.
+	// Defaults to DefaultReconcileDelay.
+	ReconcileDelay time.Duration
+	// ReconcileTimeout bounds the search for an order whose placement is unknown, including
+	// the wait for a reconnection, defaults to DefaultReconcileTimeout
+	ReconcileTimeout time.Duration
+	// Attempts is the number of times an order is sent until it is placed, defaults to DefaultPlaceAttempts
+	Attempts int
+
+	client  *Client
+	prefix  string
+	session string
+	seq     uint64
+}
+
+// NewOrderPlacer creates an OrderPlacer of client labelling orders prefix-<session>-<sequence>
+func NewOrderPlacer(client *Client, prefix string) *OrderPlacer {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return &OrderPlacer{
+		Timeout:          DefaultPlaceTimeout,
+		ReconcileDelay:   DefaultReconcileDelay,
+		ReconcileTimeout: DefaultReconcileTimeout,
+		Attempts:         DefaultPlaceAttempts,
+		client:           client,
+		prefix:           prefix,
+		session:          hex.EncodeToString(b),
+	}
+}
+
+// NewLabel returns a label unique to the placer, random across processes
+func (p *OrderPlacer) NewLabel() string {
+	return fmt.Sprintf("%v-%v-%v", p.prefix, p.session, atomic.AddUint64(&p.seq, 1))
+}
+
+// Place places req at most once and returns its order. The order is labelled by
+// NewLabel unless req has a label, req itself is unchanged. Orders rejected by the
+// exchange are not retried.
+func (p *OrderPlacer) Place(ctx context.Context, req *OrderRequest) (models.Order, error) {
+	if req.params.Label == "" {
+		req = req.Label(p.NewLabel())
+	}
+	if err := req.Validate(); err != nil {
+		return models.Order{}, err
+	}
+	instrumentName, label := req.params.InstrumentName, req.params.Label
+	since := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		result, err := p.client.placeOrder(ctx, req)
+		if err == nil {
+			return result.Order, nil
+		}
+		if _, ok := err.(*jsonrpc2.Error); ok || err == ErrAuthenticationIsRequired {
+			return models.Order{}, err
+		}
+		if ctx.Err() != nil {
+			// the order may still be placed
+			return models.Order{}, ErrOrderUnconfirmed
+		}
+		if err != ErrNotConnected {
+			// the connection dropped before the response, the order may have been placed
+			order, found, rerr := p.reconcile(ctx, instrumentName, label, since)
+			if rerr != nil {
+				return models.Order{}, ErrOrderUnconfirmed
+			}
+			if found {
+				return order, nil
+			}
+		}
+		if attempt >= p.Attempts {
+			return models.Order{}, err
+		}
+		if err := sleepContext(ctx, p.ReconcileDelay); err != nil {
+			return models.Order{}, err
+		}
+	}
+}
+
+// reconcile searches instrumentName for the order labelled label, placed after since,
+// until it knows whether it was placed, a lookup is rejected or ReconcileTimeout elapses
+func (p *OrderPlacer) reconcile(ctx context.Context, instrumentName string, label string, since time.Time) (models.Order, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.ReconcileTimeout)
+	defer cancel()
+	for {
+		if err := sleepContext(ctx, p.ReconcileDelay); err != nil {
+			return models.Order{}, false, err
+		}
+		if !p.client.IsConnected() {
+			continue
+		}
+		order, found, err := p.find(ctx, instrumentName, label, since)
+		if err == nil {
+			return order, found, nil
+		}
+		if _, ok := err.(*jsonrpc2.Error); ok || err == ErrAuthenticationIsRequired {
+			return models.Order{}, false, err
+		}
+	}
+}
+
+// find searches the open orders of instrumentName and its order history back to since for label
+func (p *OrderPlacer) find(ctx context.Context, instrumentName string, label string, since time.Time) (models.Order, bool, error) {
+	var open []models.Order
+	err := p.call(ctx, "private/get_open_orders_by_instrument",
+		&models.GetOpenOrdersByInstrumentParams{InstrumentName: instrumentName}, &open)
+	if err != nil {
+		return models.Order{}, false, err
+	}
+	if order, ok := findLabel(open, label); ok {
+		return order, true, nil
+	}
+
+	params := models.GetOrderHistoryByInstrumentParams{InstrumentName: instrumentName, IncludeUnfilled: true}
+	it := orderHistoryIterator(ctx, func(offset int, count int) ([]models.Order, error) {
+		params.Offset, params.Count = offset, count
+		var orders []models.Order
+		err := p.call(ctx, "private/get_order_history_by_instrument", &params, &orders)
+		return orders, err
+	})
+	it.SetPageSize(reconcilePageSize)
+	start := millis(since.Add(-reconcileClockSkew))
+	for it.Next() {
+		order := it.Order()
+		if order.Label == label {
+			return order, true, nil
+		}
+		if order.LastUpdateTimestamp < start {
+			// older orders were created before the first placement
+			break
+		}
+	}
+	return models.Order{}, false, it.Err()
+}
+
+// call calls method bounded by Timeout
+func (p *OrderPlacer) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+	return p.client.CallContext(ctx, method, params, result)
+}
+
+func findLabel(orders []models.Order, label string) (models.Order, bool) {
+	for _, order := range orders {
+		if order.Label == label {
+			return order, true
+		}
+	}
+	return models.Order{}, false
+}
+
+// sleepContext waits for d or until ctx ends
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
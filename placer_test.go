@@ -0,0 +1,155 @@
+package deribit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestPlacer(client *Client) *OrderPlacer {
+	placer := NewOrderPlacer(client, "test")
+	placer.Timeout = 100 * time.Millisecond
+	placer.ReconcileDelay = 20 * time.Millisecond
+	placer.ReconcileTimeout = 5 * time.Second
+	return placer
+}
+
+// newReconnectingClient returns a client of server reconnecting when disconnected
+func newReconnectingClient(server *deribittest.Server) *Client {
+	return New(&Configuration{
+		Addr:          server.URL,
+		ApiKey:        "key",
+		SecretKey:     "secret",
+		AutoReconnect: true,
+	})
+}
+
+func TestOrderPlacer_NewLabel(t *testing.T) {
+	placer := NewOrderPlacer(nil, "mm")
+	a, b := placer.NewLabel(), placer.NewLabel()
+	assert.NotEqual(t, a, b)
+	assert.True(t, strings.HasPrefix(a, "mm-"))
+	assert.NotEqual(t, a, NewOrderPlacer(nil, "mm").NewLabel())
+}
+
+func TestMock_OrderPlacerLate(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	// the first buy is placed but answered after the timeout
+	buy := server.Handler("private/buy")
+	var calls int32
+	server.Handle("private/buy", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		result, err := buy(conn, params)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(300 * time.Millisecond)
+		}
+		return result, err
+	})
+
+	client := newMockClient(server)
+	placer := newTestPlacer(client)
+	req := Limit("BTC-PERPETUAL").Buy(10).At(6000)
+	order, err := placer.Place(context.Background(), req)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(order.Label, "test-"))
+	assert.Empty(t, req.Params().Label, "the request is unchanged")
+	assert.Equal(t, 1, server.CallCount("private/buy"))
+	assert.Equal(t, 0, server.CallCount("private/get_open_orders_by_instrument"), "nothing is looked up while the call can complete")
+	assert.Len(t, server.Orders(), 1)
+}
+
+func TestMock_OrderPlacerDisconnected(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SeedBook("BTC-PERPETUAL", nil, [][]float64{{6000, 100000}})
+
+	// the first buy fills, then 150 newer orders push it to the second page
+	// of the order history before the connection drops
+	buy := server.Handler("private/buy")
+	var calls int32
+	server.Handle("private/buy", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		result, err := buy(conn, params)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			for i := 0; i < 150; i++ {
+				other, _ := json.Marshal(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: models.OrderTypeMarket})
+				buy(conn, other)
+			}
+			server.DisconnectAll()
+		}
+		return result, err
+	})
+
+	client := newReconnectingClient(server)
+	placer := newTestPlacer(client)
+	order, err := placer.Place(context.Background(), Market("BTC-PERPETUAL").Buy(10).Label("mm-1"))
+	assert.Nil(t, err)
+	assert.Equal(t, "mm-1", order.Label)
+	assert.Equal(t, 1, server.CallCount("private/buy"))
+	assert.Equal(t, 2, server.CallCount("private/get_order_history_by_instrument"))
+	assert.Len(t, server.Orders(), 151)
+}
+
+func TestMock_OrderPlacerLost(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	// the connection drops before the first sell reaches the book
+	sell := server.Handler("private/sell")
+	var calls int32
+	server.Handle("private/sell", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			server.DisconnectAll()
+			return nil, deribittest.Error(10000, "lost")
+		}
+		return sell(conn, params)
+	})
+
+	client := newReconnectingClient(server)
+	placer := newTestPlacer(client)
+	order, err := placer.Place(context.Background(), Limit("BTC-PERPETUAL").Sell(10).At(6500).Label("mm-1"))
+	assert.Nil(t, err)
+	assert.Equal(t, "mm-1", order.Label)
+	assert.Equal(t, models.DirectionSell, order.Direction)
+	assert.Equal(t, 2, server.CallCount("private/sell"))
+	assert.Len(t, server.Orders(), 1)
+}
+
+func TestMock_OrderPlacerRejected(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.RespondError("private/buy", 10009, "not_enough_funds")
+
+	client := newReconnectingClient(server)
+	placer := newTestPlacer(client)
+	_, err := placer.Place(context.Background(), Limit("BTC-PERPETUAL").Buy(10).At(6000))
+	assert.EqualError(t, err, "jsonrpc2: code 10009 message: not_enough_funds")
+	assert.Equal(t, 1, server.CallCount("private/buy"))
+	assert.Equal(t, 0, server.CallCount("private/get_open_orders_by_instrument"))
+
+	// placements unknown when the context ends are unconfirmed
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	server.Handle("private/buy", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		time.Sleep(300 * time.Millisecond)
+		return nil, deribittest.Error(10000, "late")
+	})
+	_, err = placer.Place(ctx, Limit("BTC-PERPETUAL").Buy(10).At(6000))
+	assert.Equal(t, ErrOrderUnconfirmed, err)
+
+	// and so are those whose lookup is rejected
+	server.Handle("private/buy", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		server.DisconnectAll()
+		return nil, deribittest.Error(10000, "lost")
+	})
+	server.RespondError("private/get_open_orders_by_instrument", 10028, "too_many_requests")
+	_, err = placer.Place(context.Background(), Limit("BTC-PERPETUAL").Buy(10).At(6000))
+	assert.Equal(t, ErrOrderUnconfirmed, err)
+	assert.Equal(t, 1, server.CallCount("private/get_open_orders_by_instrument"))
+}
@@ -0,0 +1,97 @@
+package deribit
+
+import (
+	"github.com/chuckpreslar/emission"
+	"strings"
+	"sync"
+)
+
+// DefaultPoolShards is the number of market data connections of a Pool
+// created without an explicit count
+const DefaultPoolShards = 2
+
+// Pool is a set of connections presented as a single Client.
+// API calls and private (user.*) channels use a dedicated trading connection,
+// public channels are sharded across the market data connections so heavy
+// market data subscriptions don't delay order traffic.
+// Listeners registered with On receive events from every connection.
+type Pool struct {
+	*Client
+
+	shards []*Client
+
+	mu       sync.Mutex
+	assigned map[string]*Client
+	load     map[*Client]int
+}
+
+// NewPool creates a Pool with one trading connection and shards market data
+// connections, all configured by cfg
+func NewPool(cfg *Configuration, shards int) *Pool {
+	if shards <= 0 {
+		shards = DefaultPoolShards
+	}
+	emitter := emission.NewEmitter()
+	p := &Pool{
+		Client:   newClientWithEmitter(cfg, emitter),
+		assigned: make(map[string]*Client),
+		load:     make(map[*Client]int),
+	}
+	for i := 0; i < shards; i++ {
+		p.shards = append(p.shards, newClientWithEmitter(cfg, emitter))
+	}
+	return p
+}
+
+// Trading returns the connection used for API calls and private channels
+func (p *Pool) Trading() *Client {
+	return p.Client
+}
+
+// Shards returns the market data connections
+func (p *Pool) Shards() []*Client {
+	return p.shards
+}
+
+// Subscribe subscribes channels, private channels on the trading connection and
+// public channels on the least loaded market data connection.
+// A channel already subscribed stays on its connection.
+func (p *Pool) Subscribe(channels []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	batches := make(map[*Client][]string)
+	for _, channel := range channels {
+		if _, ok := p.assigned[channel]; ok {
+			continue
+		}
+		client := p.Client
+		if !strings.HasPrefix(channel, "user.") {
+			client = p.leastLoaded()
+		}
+		p.assigned[channel] = client
+		p.load[client]++
+		batches[client] = append(batches[client], channel)
+	}
+	for client, batch := range batches {
+		client.Subscribe(batch)
+	}
+}
+
+// ConnectionOf returns the connection channel is subscribed on, or nil
+func (p *Pool) ConnectionOf(channel string) *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.assigned[channel]
+}
+
+func (p *Pool) leastLoaded() *Client {
+	best := p.shards[0]
+	for _, shard := range p.shards[1:] {
+		if p.load[shard] < p.load[best] {
+			best = shard
+		}
+	}
+	return best
+}
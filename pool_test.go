@@ -0,0 +1,55 @@
+package deribit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPool_Subscribe(t *testing.T) {
+	server := newWSTestServer(false)
+	defer server.Close()
+
+	pool := NewPool(&Configuration{Addr: server.URL}, 2)
+	assert.Len(t, pool.Shards(), 2)
+	trades := make(chan *models.TradesNotification, 4)
+	pool.On("trades.BTC-PERPETUAL.raw", func(e *models.TradesNotification) {
+		trades <- e
+	})
+	pool.Subscribe([]string{"trades.BTC-PERPETUAL.raw", "trades.ETH-PERPETUAL.raw"})
+	pool.Subscribe([]string{"book.BTC-PERPETUAL.raw", "trades.BTC-PERPETUAL.raw", "user.orders.BTC-PERPETUAL.raw"})
+
+	assert.Equal(t, pool.Trading(), pool.ConnectionOf("user.orders.BTC-PERPETUAL.raw"))
+	assert.Equal(t, pool.Shards()[0], pool.ConnectionOf("trades.BTC-PERPETUAL.raw"))
+	assert.Equal(t, pool.Shards()[1], pool.ConnectionOf("trades.ETH-PERPETUAL.raw"))
+	assert.Equal(t, pool.Shards()[0], pool.ConnectionOf("book.BTC-PERPETUAL.raw"))
+	assert.Nil(t, pool.ConnectionOf("ticker.BTC-PERPETUAL.raw"))
+
+	// connections are accepted in the order NewPool dials them:
+	// trading connection first, then the shards
+	conns := server.Conns()
+	assert.Len(t, conns, 3)
+	assert.Equal(t, []string{"user.orders.BTC-PERPETUAL.raw"}, conns[0].Channels())
+	assert.Equal(t, []string{"trades.BTC-PERPETUAL.raw", "book.BTC-PERPETUAL.raw"}, conns[1].Channels())
+	assert.Equal(t, []string{"trades.ETH-PERPETUAL.raw"}, conns[2].Channels())
+
+	err := conns[1].Notify(context.Background(), "subscription", &Event{
+		Channel: "trades.BTC-PERPETUAL.raw",
+		Data:    json.RawMessage(`[{"trade_seq":1,"trade_id":"1","instrument_name":"BTC-PERPETUAL","price":6000,"amount":10}]`),
+	})
+	assert.Nil(t, err)
+	select {
+	case e := <-trades:
+		assert.Equal(t, 1, (*e)[0].TradeSeq)
+	case <-time.After(time.Second):
+		t.Fatal("no trades notification")
+	}
+	select {
+	case <-trades:
+		t.Fatal("duplicate trades notification")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
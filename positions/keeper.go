@@ -0,0 +1,392 @@
+package positions
+
+import (
+	"github.com/chuckpreslar/emission"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/models"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultReconcileInterval is the reconciliation interval of a Keeper started without one
+const DefaultReconcileInterval = time.Minute
+
+// Keeper tracks the positions of an account and their profit and loss.
+//
+// Positions are seeded from get_positions and updated from the trades of the
+// user.changes channels, in trade_seq order, and priced from the ticker channels
+// of their instruments. Trades notified around a Seed may be counted twice or
+// missed; Reconcile, run periodically once started, corrects any such drift.
+// Listeners run on the notification goroutine and must not call the Client.
+type Keeper struct {
+	client     *deribit.Client
+	currencies []string
+	emitter    *emission.Emitter
+
+	mu        sync.Mutex
+	positions map[string]*Position
+	tickers   map[string]bool // instruments whose ticker is subscribed
+	subscribe chan struct{}
+	stop      chan struct{}
+	listening bool
+}
+
+// NewKeeper creates a Keeper of the positions of currencies, all currencies if none
+func NewKeeper(client *deribit.Client, currencies ...string) *Keeper {
+	return &Keeper{
+		client:     client,
+		currencies: currencies,
+		emitter:    emission.NewEmitter(),
+		positions:  make(map[string]*Position),
+		tickers:    make(map[string]bool),
+		subscribe:  make(chan struct{}, 1),
+	}
+}
+
+// On adds a listener func(*Position) for EventUpdate or func(*Drift) for EventDrift
+func (k *Keeper) On(event interface{}, listener interface{}) *emission.Emitter {
+	return k.emitter.On(event, listener)
+}
+
+// Off removes a listener
+func (k *Keeper) Off(event interface{}, listener interface{}) *emission.Emitter {
+	return k.emitter.Off(event, listener)
+}
+
+// Seed replaces the positions with those of the exchange, e.g. after a restart or a
+// reconnect. RealizedPnL and Fees restart from zero, the session realized profit and
+// loss of the exchange is kept apart in SessionRealizedPnL
+func (k *Keeper) Seed() error {
+	exchange, err := k.fetch()
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	for name := range k.positions {
+		if _, ok := exchange[name]; !ok {
+			exchange[name] = models.Position{InstrumentName: name}
+		}
+	}
+	var updates []*Position
+	for _, name := range sortedNames(exchange) {
+		e := exchange[name]
+		p := k.position(name)
+		p.adopt(&e)
+		p.RealizedPnL = 0
+		p.RealizedPnLUSD = 0
+		p.Fees = 0
+		p.tradeSeq = 0
+		position := *p
+		updates = append(updates, &position)
+	}
+	k.mu.Unlock()
+	k.subscribeLater()
+	for _, p := range updates {
+		k.emitter.Emit(EventUpdate, p)
+	}
+	return nil
+}
+
+// Reconcile compares the positions with those of the exchange, emitting EventDrift
+// and adopting the size and average price of the exchange for every position which differs.
+// Positions traded while the exchange positions are fetched are left to the next Reconcile
+func (k *Keeper) Reconcile() ([]Drift, error) {
+	seqs := k.tradeSeqs()
+	exchange, err := k.fetch()
+	if err != nil {
+		return nil, err
+	}
+	k.mu.Lock()
+	for name := range k.positions {
+		if _, ok := exchange[name]; !ok {
+			exchange[name] = models.Position{InstrumentName: name}
+		}
+	}
+	var drifts []Drift
+	var updates []*Position
+	for _, name := range sortedNames(exchange) {
+		e := exchange[name]
+		p, ok := k.positions[name]
+		if !ok && e.Size == 0 {
+			continue
+		}
+		if ok && p.tradeSeq != seqs[name] {
+			// the fetched position may predate the trade
+			continue
+		}
+		p = k.position(name)
+		if !p.drifted(&e) {
+			continue
+		}
+		drifts = append(drifts, Drift{InstrumentName: name, Local: *p, Exchange: e})
+		p.adopt(&e)
+		position := *p
+		updates = append(updates, &position)
+	}
+	k.mu.Unlock()
+	k.subscribeLater()
+	for i := range drifts {
+		drift := drifts[i]
+		k.emitter.Emit(EventDrift, &drift)
+	}
+	for _, p := range updates {
+		k.emitter.Emit(EventUpdate, p)
+	}
+	return drifts, nil
+}
+
+// Start subscribes to the changes of the currencies and the tickers of the positions,
+// and reconciles the positions every interval until Stop
+func (k *Keeper) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReconcileInterval
+	}
+	k.mu.Lock()
+	if k.stop != nil {
+		k.mu.Unlock()
+		return
+	}
+	k.stop = make(chan struct{})
+	stop := k.stop
+	listening := k.listening
+	k.listening = true
+	k.mu.Unlock()
+
+	if !listening {
+		currencies := k.currencies
+		if len(currencies) == 0 {
+			currencies = []string{"any"}
+		}
+		var channels []string
+		for _, currency := range currencies {
+			channel := "user.changes.any." + currency + ".raw"
+			// emission removes listeners by code pointer, so the listeners stay
+			// registered and ignore notifications while stopped
+			k.client.On(channel, k.onChanges)
+			channels = append(channels, channel)
+		}
+		k.client.Subscribe(channels)
+	}
+	k.subscribeLater()
+	go k.run(stop, interval)
+}
+
+// Stop stops updating the positions
+func (k *Keeper) Stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.stop == nil {
+		return
+	}
+	close(k.stop)
+	k.stop = nil
+}
+
+// Position returns the position of instrumentName
+func (k *Keeper) Position(instrumentName string) (Position, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	p, ok := k.positions[instrumentName]
+	if !ok {
+		return Position{}, false
+	}
+	return *p, true
+}
+
+// Positions returns every position, including flat ones, by instrument name
+func (k *Keeper) Positions() []Position {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	result := make([]Position, 0, len(k.positions))
+	for _, p := range k.positions {
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].InstrumentName < result[j].InstrumentName
+	})
+	return result
+}
+
+func (k *Keeper) onChanges(e *models.UserChangesNotification) {
+	k.mu.Lock()
+	if k.stop == nil {
+		k.mu.Unlock()
+		return
+	}
+	changed := make(map[string]*Position)
+	trades := append([]models.UserTrade(nil), e.Trades...)
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].TradeSeq < trades[j].TradeSeq
+	})
+	for i := range trades {
+		trade := &trades[i]
+		p := k.position(trade.InstrumentName)
+		if trade.TradeSeq <= p.tradeSeq {
+			continue
+		}
+		p.applyTrade(trade)
+		changed[p.InstrumentName] = p
+	}
+	for _, update := range e.Positions {
+		p, ok := k.positions[update.InstrumentName]
+		if !ok || (update.MarkPrice <= 0 && update.IndexPrice <= 0) {
+			continue
+		}
+		if update.MarkPrice > 0 {
+			p.MarkPrice = update.MarkPrice
+		}
+		if update.IndexPrice > 0 {
+			p.IndexPrice = update.IndexPrice
+		}
+		p.revalue()
+		changed[p.InstrumentName] = p
+	}
+	var updates []*Position
+	for _, name := range sortedKeys(changed) {
+		position := *changed[name]
+		updates = append(updates, &position)
+	}
+	k.mu.Unlock()
+	k.subscribeLater()
+	for _, p := range updates {
+		k.emitter.Emit(EventUpdate, p)
+	}
+}
+
+func (k *Keeper) onTicker(e *models.TickerNotification) {
+	k.mu.Lock()
+	p, ok := k.positions[e.InstrumentName]
+	if k.stop == nil || !ok {
+		k.mu.Unlock()
+		return
+	}
+	if e.MarkPrice > 0 {
+		p.MarkPrice = e.MarkPrice
+	}
+	if e.IndexPrice > 0 {
+		p.IndexPrice = e.IndexPrice
+	}
+	p.revalue()
+	position := *p
+	k.mu.Unlock()
+	k.emitter.Emit(EventUpdate, &position)
+}
+
+func (k *Keeper) run(stop chan struct{}, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if _, err := k.Reconcile(); err != nil {
+				log.Printf("position keeper: %v", err)
+			}
+		case <-k.subscribe:
+			k.subscribeTickers()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// subscribeLater asks run to subscribe to the tickers of new positions,
+// calls from a listener would block the connection
+func (k *Keeper) subscribeLater() {
+	select {
+	case k.subscribe <- struct{}{}:
+	default:
+	}
+}
+
+// subscribeTickers subscribes to the tickers of the positions which are not subscribed yet
+func (k *Keeper) subscribeTickers() {
+	k.mu.Lock()
+	var channels []string
+	for name := range k.positions {
+		if k.tickers[name] {
+			continue
+		}
+		k.tickers[name] = true
+		channels = append(channels, "ticker."+name+".100ms")
+	}
+	k.mu.Unlock()
+
+	if len(channels) == 0 {
+		return
+	}
+	sort.Strings(channels)
+	for _, channel := range channels {
+		k.client.On(channel, k.onTicker)
+	}
+	k.client.Subscribe(channels)
+}
+
+// tradeSeqs returns the last trade applied to every position by instrument name
+func (k *Keeper) tradeSeqs() map[string]int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	seqs := make(map[string]int, len(k.positions))
+	for name, p := range k.positions {
+		seqs[name] = p.tradeSeq
+	}
+	return seqs
+}
+
+// position returns the position of name, creating a flat one if unknown
+func (k *Keeper) position(name string) *Position {
+	p, ok := k.positions[name]
+	if !ok {
+		p = newPosition(name)
+		k.positions[name] = p
+	}
+	return p
+}
+
+// fetch returns the exchange positions of the currencies by instrument name
+func (k *Keeper) fetch() (map[string]models.Position, error) {
+	currencies := k.currencies
+	if len(currencies) == 0 {
+		all, err := k.client.GetCurrencies()
+		if err != nil {
+			return nil, err
+		}
+		for _, currency := range all {
+			currencies = append(currencies, currency.Currency)
+		}
+	}
+	result := make(map[string]models.Position)
+	for _, currency := range currencies {
+		positions, err := k.client.GetPositions(&models.GetPositionsParams{Currency: currency})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range positions {
+			result[p.InstrumentName] = p
+		}
+	}
+	return result, nil
+}
+
+func sortedNames(positions map[string]models.Position) []string {
+	names := make([]string, 0, len(positions))
+	for name := range positions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(positions map[string]*Position) []string {
+	names := make([]string, 0, len(positions))
+	for name := range positions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,197 @@
+package positions
+
+import (
+	"encoding/json"
+	"github.com/frankrap/deribit-api"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newClient(server *deribittest.Server) *deribit.Client {
+	return deribit.New(&deribit.Configuration{
+		Addr:      server.URL,
+		ApiKey:    "key",
+		SecretKey: "secret",
+	})
+}
+
+func TestPosition_ApplyTrade(t *testing.T) {
+	future := newPosition("BTC-PERPETUAL")
+	assert.True(t, future.Inverse)
+	assert.Equal(t, "BTC", future.Currency)
+	future.applyTrade(&models.UserTrade{Direction: models.DirectionBuy, Amount: 100, Price: 10000, TradeSeq: 1})
+	future.applyTrade(&models.UserTrade{Direction: models.DirectionBuy, Amount: 100, Price: 20000, TradeSeq: 2})
+	assert.InDelta(t, 200.0/(100.0/10000+100.0/20000), future.AveragePrice, 1e-9, "inverse entries average harmonically")
+
+	future.applyTrade(&models.UserTrade{Direction: models.DirectionSell, Amount: 300, Price: 20000, IndexPrice: 20000, TradeSeq: 3})
+	assert.Equal(t, -100.0, future.Size)
+	assert.Equal(t, 20000.0, future.AveragePrice, "flipped positions enter at the trade price")
+	assert.InDelta(t, 0.005, future.RealizedPnL, 1e-12)
+	assert.InDelta(t, 100.0, future.RealizedPnLUSD, 1e-9)
+
+	future.MarkPrice, future.IndexPrice = 25000, 24000
+	future.revalue()
+	assert.InDelta(t, -100*(1.0/20000-1.0/25000), future.UnrealizedPnL, 1e-12)
+	assert.InDelta(t, future.UnrealizedPnL*24000, future.UnrealizedPnLUSD, 1e-9)
+
+	option := newPosition("BTC-27DEC24-60000-C")
+	assert.Equal(t, models.KindOption, option.Kind)
+	assert.False(t, option.Inverse)
+	option.applyTrade(&models.UserTrade{Direction: models.DirectionBuy, Amount: 1, Price: 0.1, Fee: 0.0003, TradeSeq: 1})
+	option.applyTrade(&models.UserTrade{Direction: models.DirectionSell, Amount: 1, Price: 0.15, Fee: 0.0003, TradeSeq: 2})
+	assert.Equal(t, 0.0, option.Size)
+	assert.Equal(t, 0.0, option.AveragePrice)
+	assert.InDelta(t, 0.05, option.RealizedPnL, 1e-12)
+	assert.InDelta(t, 0.0006, option.Fees, 1e-12)
+
+	// linear USDC futures are sized in the base currency, with profit and loss in USDC
+	linear := newPosition("BTC_USDC-PERPETUAL")
+	assert.Equal(t, models.KindFuture, linear.Kind)
+	assert.False(t, linear.Inverse)
+	assert.Equal(t, "USDC", linear.Currency)
+	linear.applyTrade(&models.UserTrade{Direction: models.DirectionBuy, Amount: 1, Price: 10000, IndexPrice: 10000, TradeSeq: 1})
+	linear.applyTrade(&models.UserTrade{Direction: models.DirectionBuy, Amount: 1, Price: 20000, IndexPrice: 20000, TradeSeq: 2})
+	assert.Equal(t, 15000.0, linear.AveragePrice, "linear entries average arithmetically")
+	linear.applyTrade(&models.UserTrade{Direction: models.DirectionSell, Amount: 1, Price: 16000, IndexPrice: 16000, TradeSeq: 3})
+	assert.InDelta(t, 1000.0, linear.RealizedPnL, 1e-9)
+	assert.InDelta(t, 1000.0, linear.RealizedPnLUSD, 1e-9, "USDC counts as USD")
+	linear.MarkPrice, linear.IndexPrice = 17000, 17000
+	linear.revalue()
+	assert.InDelta(t, 2000.0, linear.UnrealizedPnL, 1e-9)
+	assert.InDelta(t, 2000.0, linear.UnrealizedPnLUSD, 1e-9)
+}
+
+func TestKeeper(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SeedBook("BTC-PERPETUAL", nil, [][]float64{{6000, 1000}})
+	client := newClient(server)
+
+	_, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 100, Type: models.OrderTypeMarket})
+	assert.Nil(t, err)
+
+	keeper := NewKeeper(client, "BTC")
+	var mu sync.Mutex
+	var updates []*Position
+	keeper.On(EventUpdate, func(p *Position) {
+		mu.Lock()
+		defer mu.Unlock()
+		updates = append(updates, p)
+	})
+	assert.Nil(t, keeper.Seed())
+	p, ok := keeper.Position("BTC-PERPETUAL")
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, p.Size)
+	assert.Equal(t, 6000.0, p.AveragePrice)
+
+	keeper.Start(time.Hour)
+	defer keeper.Stop()
+	assert.True(t, server.WaitSubscribed("user.changes.any.BTC.raw", time.Second))
+	assert.True(t, server.WaitSubscribed("ticker.BTC-PERPETUAL.100ms", time.Second))
+
+	_, err = client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 100, Type: models.OrderTypeMarket})
+	assert.Nil(t, err)
+	server.SeedBook("BTC-PERPETUAL", [][]float64{{6600, 1000}}, nil)
+	_, err = client.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 300, Type: models.OrderTypeMarket})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		p, _ := keeper.Position("BTC-PERPETUAL")
+		return p.Size == -100
+	}, time.Second, 10*time.Millisecond)
+	p, _ = keeper.Position("BTC-PERPETUAL")
+	assert.Equal(t, 6600.0, p.AveragePrice)
+	assert.InDelta(t, 200*(1.0/6000-1.0/6600), p.RealizedPnL, 1e-12)
+
+	server.Notify("ticker.BTC-PERPETUAL.100ms", models.TickerNotification{InstrumentName: "BTC-PERPETUAL", MarkPrice: 6000, IndexPrice: 5900})
+	assert.Eventually(t, func() bool {
+		p, _ := keeper.Position("BTC-PERPETUAL")
+		return p.MarkPrice == 6000
+	}, time.Second, 10*time.Millisecond)
+	p, _ = keeper.Position("BTC-PERPETUAL")
+	assert.InDelta(t, -100*(1.0/6600-1.0/6000), p.UnrealizedPnL, 1e-12)
+	assert.InDelta(t, p.UnrealizedPnL*5900, p.UnrealizedPnLUSD, 1e-9)
+
+	drifts, err := keeper.Reconcile()
+	assert.Nil(t, err)
+	assert.Empty(t, drifts)
+	assert.Len(t, keeper.Positions(), 1)
+
+	mu.Lock()
+	assert.NotEmpty(t, updates)
+	mu.Unlock()
+}
+
+func TestKeeper_Drift(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	client := newClient(server)
+	exchange := []models.Position{{InstrumentName: "BTC-PERPETUAL", Kind: models.KindFuture, Size: 100, AveragePrice: 6000, MarkPrice: 6100, IndexPrice: 6100, RealizedProfitLoss: 0.002}}
+	server.Handle("private/get_positions", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		return exchange, nil
+	})
+
+	keeper := NewKeeper(client, "BTC")
+	var drifted []*Drift
+	keeper.On(EventDrift, func(d *Drift) {
+		drifted = append(drifted, d)
+	})
+	assert.Nil(t, keeper.Seed())
+	p, _ := keeper.Position("BTC-PERPETUAL")
+	assert.Equal(t, 0.002, p.SessionRealizedPnL)
+	assert.Equal(t, 0.0, p.RealizedPnL, "the session realized profit and loss of the exchange is kept apart")
+	drifts, err := keeper.Reconcile()
+	assert.Nil(t, err)
+	assert.Empty(t, drifts)
+
+	exchange[0].Size = 150
+	drifts, err = keeper.Reconcile()
+	assert.Nil(t, err)
+	if assert.Len(t, drifts, 1) {
+		assert.Equal(t, 100.0, drifts[0].Local.Size)
+		assert.Equal(t, 150.0, drifts[0].Exchange.Size)
+	}
+	assert.Len(t, drifted, 1)
+	p, _ = keeper.Position("BTC-PERPETUAL")
+	assert.Equal(t, 150.0, p.Size, "the exchange position is adopted")
+	assert.InDelta(t, 150*(1.0/6000-1.0/6100), p.UnrealizedPnL, 1e-12)
+}
+
+func TestKeeper_ReconcileTraded(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	client := newClient(server)
+	exchange := []models.Position{{InstrumentName: "BTC-PERPETUAL", Kind: models.KindFuture, Size: 100, AveragePrice: 6000, MarkPrice: 6000, IndexPrice: 6000}}
+	keeper := NewKeeper(client, "BTC")
+	var trade func()
+	server.Handle("private/get_positions", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		if trade != nil {
+			// a trade is applied while the positions are fetched
+			trade()
+		}
+		return exchange, nil
+	})
+	assert.Nil(t, keeper.Seed())
+	keeper.Start(time.Hour)
+	defer keeper.Stop()
+
+	trade = func() {
+		keeper.onChanges(&models.UserChangesNotification{Trades: []models.UserTrade{
+			{InstrumentName: "BTC-PERPETUAL", Direction: models.DirectionBuy, Amount: 50, Price: 6000, TradeSeq: 1},
+		}})
+	}
+	drifts, err := keeper.Reconcile()
+	assert.Nil(t, err)
+	assert.Empty(t, drifts, "positions traded during the fetch are not compared")
+	p, _ := keeper.Position("BTC-PERPETUAL")
+	assert.Equal(t, 150.0, p.Size)
+
+	trade = nil
+	exchange[0].Size = 150
+	drifts, err = keeper.Reconcile()
+	assert.Nil(t, err)
+	assert.Empty(t, drifts)
+}
@@ -0,0 +1,176 @@
+package positions
+
+import (
+	"github.com/frankrap/deribit-api/models"
+	"math"
+)
+
+// Events emitted by a Keeper
+const (
+	// EventUpdate is emitted with a *Position when a trade or a price changes a position
+	EventUpdate = "update"
+	// EventDrift is emitted with a *Drift when a position differs from the exchange
+	EventDrift = "drift"
+)
+
+const (
+	// sizeTolerance is the size difference ignored by reconciliation
+	sizeTolerance = 1e-9
+	// priceTolerance is the relative average price difference ignored by reconciliation
+	priceTolerance = 1e-6
+)
+
+// Position is a position tracked by a Keeper.
+//
+// Inverse futures are sized in USD and their profit and loss in the base currency
+// is size * (1/entry - 1/exit). Options and linear USDC futures are linear: their
+// size is in the base currency and their profit and loss, size * (exit - entry), is
+// in the currency of their prices, the base currency for options of inverse
+// currencies and the quote currency for USDC instruments. USD values are converted
+// at the index price, quote currencies count as USD.
+type Position struct {
+	InstrumentName string
+	Kind           models.Kind
+	// Inverse is set for futures settled in the base currency
+	Inverse bool
+	// Currency is the currency of the profit and loss
+	Currency string
+	// Size is negative for short positions
+	Size float64
+	// AveragePrice is the average entry price of the size, 0 when flat
+	AveragePrice float64
+	MarkPrice    float64
+	IndexPrice   float64
+	// RealizedPnL is the profit and loss of the size closed since the last Seed, before fees
+	RealizedPnL float64
+	// RealizedPnLUSD is RealizedPnL converted at the index price of every closing trade
+	RealizedPnLUSD float64
+	// UnrealizedPnL is the profit and loss of the size at the mark price
+	UnrealizedPnL float64
+	// UnrealizedPnLUSD is UnrealizedPnL converted at the current index price
+	UnrealizedPnLUSD float64
+	// Fees are the fees of the trades applied since the last Seed, in their fee currency
+	Fees float64
+	// SessionRealizedPnL is the realized profit and loss of the session as reported by the
+	// exchange when the position was last seeded or adopted, including funding, apart
+	// from RealizedPnL
+	SessionRealizedPnL float64
+
+	quoted   bool // the profit and loss is in a quote currency counted as USD
+	tradeSeq int  // the last trade applied
+}
+
+// Drift is a position which differed from the exchange when reconciled,
+// the Keeper then adopts the size and average price of the exchange
+type Drift struct {
+	InstrumentName string
+	Local          Position
+	Exchange       models.Position
+}
+
+// newPosition returns a flat position of instrumentName, unparsable names are taken for inverse futures
+func newPosition(instrumentName string) *Position {
+	p := &Position{InstrumentName: instrumentName, Kind: models.KindFuture, Inverse: true}
+	id, err := models.ParseInstrumentID(instrumentName)
+	if err != nil {
+		return p
+	}
+	p.Kind, p.Inverse, p.Currency = id.Kind, id.Inverse(), id.Currency
+	if id.Quote != "" {
+		p.Currency, p.quoted = id.Quote, true
+	}
+	return p
+}
+
+// pnl returns the profit and loss of size entered at entry and exited at exit
+func pnl(inverse bool, size float64, entry float64, exit float64) float64 {
+	if size == 0 || entry <= 0 || exit <= 0 {
+		return 0
+	}
+	if inverse {
+		return size * (1/entry - 1/exit)
+	}
+	return size * (exit - entry)
+}
+
+// applyTrade updates the size, average price and realized profit and loss of p with trade
+func (p *Position) applyTrade(trade *models.UserTrade) {
+	signed := trade.Amount
+	if trade.Direction == models.DirectionSell {
+		signed = -trade.Amount
+	}
+	size := p.Size + signed
+	switch {
+	case p.Size == 0 || (p.Size > 0) == (signed > 0):
+		// opening or increasing, inverse futures average the entry harmonically
+		if p.Inverse && p.AveragePrice > 0 {
+			p.AveragePrice = math.Abs(size) / (math.Abs(p.Size)/p.AveragePrice + trade.Amount/trade.Price)
+		} else {
+			p.AveragePrice = (p.AveragePrice*math.Abs(p.Size) + trade.Price*trade.Amount) / math.Abs(size)
+		}
+	default:
+		// reducing, closing or flipping
+		closed := math.Min(math.Abs(signed), math.Abs(p.Size))
+		realized := pnl(p.Inverse, math.Copysign(closed, p.Size), p.AveragePrice, trade.Price)
+		p.RealizedPnL += realized
+		p.RealizedPnLUSD += p.usd(realized, trade.IndexPrice)
+		if math.Abs(size) < sizeTolerance {
+			size = 0
+			p.AveragePrice = 0
+		} else if (size > 0) != (p.Size > 0) {
+			p.AveragePrice = trade.Price
+		}
+	}
+	p.Size = size
+	p.Fees += trade.Fee
+	p.tradeSeq = trade.TradeSeq
+	if trade.IndexPrice > 0 {
+		p.IndexPrice = trade.IndexPrice
+	}
+	if trade.MarkPrice > 0 {
+		p.MarkPrice = trade.MarkPrice
+	}
+	p.revalue()
+}
+
+// revalue updates the unrealized profit and loss of p from its prices
+func (p *Position) revalue() {
+	p.UnrealizedPnL = pnl(p.Inverse, p.Size, p.AveragePrice, p.MarkPrice)
+	p.UnrealizedPnLUSD = p.usd(p.UnrealizedPnL, p.IndexPrice)
+}
+
+// usd converts value, in the currency of the profit and loss, to USD at indexPrice
+func (p *Position) usd(value float64, indexPrice float64) float64 {
+	if p.quoted {
+		return value
+	}
+	return value * indexPrice
+}
+
+// drifted reports whether p differs from the exchange position
+func (p *Position) drifted(exchange *models.Position) bool {
+	if math.Abs(p.Size-exchange.Size) > sizeTolerance {
+		return true
+	}
+	if p.Size == 0 {
+		return false
+	}
+	return math.Abs(p.AveragePrice-exchange.AveragePrice) > priceTolerance*math.Abs(exchange.AveragePrice)
+}
+
+// adopt sets the size, average price and prices of p to the exchange position
+func (p *Position) adopt(exchange *models.Position) {
+	p.Size = exchange.Size
+	p.AveragePrice = exchange.AveragePrice
+	if p.Size == 0 {
+		p.AveragePrice = 0
+	}
+	if exchange.MarkPrice > 0 {
+		p.MarkPrice = exchange.MarkPrice
+	}
+	if exchange.IndexPrice > 0 {
+		p.IndexPrice = exchange.IndexPrice
+	}
+	p.SessionRealizedPnL = exchange.RealizedProfitLoss
+	p.revalue()
+}
@@ -0,0 +1,230 @@
+package deribit
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"github.com/sourcegraph/jsonrpc2"
+	"io"
+	"sync"
+	"time"
+)
+
+// Directions of a recorded Frame
+const (
+	FrameIn  = "in"
+	FrameOut = "out"
+)
+
+// Redacted replaces credentials in recorded frames
+const Redacted = "REDACTED"
+
+// redactedFields are the params and result fields holding credentials,
+// sent to public/auth and public/exchange_token and returned by them
+var redactedFields = []string{"client_secret", "access_token", "refresh_token"}
+
+// Frame is a JSON-RPC frame of a recorded session
+type Frame struct {
+	Time time.Time       `json:"ts"`
+	Dir  string          `json:"dir"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Recorder writes the frames of WebSocket sessions as gzip compressed JSON lines,
+// set it as Configuration.Recorder to record a Client. Client secrets and access
+// and refresh tokens are replaced by Redacted.
+type Recorder struct {
+	mu  sync.Mutex
+	gz  *gzip.Writer
+	enc *json.Encoder
+	err error
+}
+
+// NewRecorder creates a Recorder writing to w, Close must be called to flush the recording
+func NewRecorder(w io.Writer) *Recorder {
+	gz := gzip.NewWriter(w)
+	return &Recorder{gz: gz, enc: json.NewEncoder(gz)}
+}
+
+// Wrap returns a jsonrpc2.ObjectStream recording every frame read from or written to stream
+func (r *Recorder) Wrap(stream jsonrpc2.ObjectStream) jsonrpc2.ObjectStream {
+	return &recordingStream{stream: stream, recorder: r}
+}
+
+// Record writes a frame, with its credentials redacted
+func (r *Recorder) Record(dir string, data []byte) error {
+	data = redact(data)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	r.err = r.enc.Encode(&Frame{Time: time.Now(), Dir: dir, Data: data})
+	return r.err
+}
+
+// Flush flushes the frames recorded so far to the underlying writer
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	return r.gz.Flush()
+}
+
+// Close flushes the recording, it does not close the underlying writer
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	if err := r.gz.Close(); err != nil {
+		r.err = err
+		return err
+	}
+	r.err = io.ErrClosedPipe
+	return nil
+}
+
+// redact returns frame with the credentials in its params and result replaced by Redacted
+func redact(frame []byte) []byte {
+	found := false
+	for _, field := range redactedFields {
+		if bytes.Contains(frame, []byte(`"`+field+`"`)) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return frame
+	}
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(frame, &message); err != nil {
+		return frame
+	}
+	changed := false
+	for _, key := range []string{"params", "result"} {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(message[key], &object); err != nil {
+			continue
+		}
+		redactedObject := false
+		for _, field := range redactedFields {
+			if _, ok := object[field]; ok {
+				object[field] = json.RawMessage(`"` + Redacted + `"`)
+				redactedObject = true
+			}
+		}
+		if redactedObject {
+			message[key], _ = json.Marshal(object)
+			changed = true
+		}
+	}
+	if !changed {
+		return frame
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return frame
+	}
+	return data
+}
+
+// recordingStream is a jsonrpc2.ObjectStream recording to a Recorder
+type recordingStream struct {
+	stream   jsonrpc2.ObjectStream
+	recorder *Recorder
+}
+
+func (s *recordingStream) WriteObject(obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	s.recorder.Record(FrameOut, data)
+	return s.stream.WriteObject(json.RawMessage(data))
+}
+
+func (s *recordingStream) ReadObject(v interface{}) error {
+	var data json.RawMessage
+	if err := s.stream.ReadObject(&data); err != nil {
+		return err
+	}
+	s.recorder.Record(FrameIn, data)
+	return json.Unmarshal(data, v)
+}
+
+func (s *recordingStream) Close() error {
+	return s.stream.Close()
+}
+
+// Replayer reads a recording written by a Recorder
+type Replayer struct {
+	gz  *gzip.Reader
+	dec *json.Decoder
+}
+
+// NewReplayer creates a Replayer reading from r
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	gz, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{gz: gz, dec: json.NewDecoder(gz)}, nil
+}
+
+// Next returns the next frame, or io.EOF at the end of the recording
+func (r *Replayer) Next() (*Frame, error) {
+	var frame Frame
+	if err := r.dec.Decode(&frame); err != nil {
+		return nil, err
+	}
+	return &frame, nil
+}
+
+// Play feeds the inbound notifications of the recording to handler, e.g. a Client.
+// speed scales the original pace: 1 plays in real time, 10 ten times faster
+// and 0 as fast as possible.
+func (r *Replayer) Play(ctx context.Context, handler jsonrpc2.Handler, speed float64) error {
+	var last time.Time
+	for {
+		frame, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if frame.Dir != FrameIn {
+			continue
+		}
+		var req jsonrpc2.Request
+		if err := json.Unmarshal(frame.Data, &req); err != nil || req.Method == "" {
+			// responses to calls
+			continue
+		}
+		if speed > 0 && !last.IsZero() {
+			if d := time.Duration(float64(frame.Time.Sub(last)) / speed); d > 0 {
+				t := time.NewTimer(d)
+				select {
+				case <-t.C:
+				case <-ctx.Done():
+					t.Stop()
+					return ctx.Err()
+				}
+			}
+		}
+		last = frame.Time
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		handler.Handle(ctx, nil, &req)
+	}
+}
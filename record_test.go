@@ -0,0 +1,93 @@
+package deribit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestRecorder_Replay(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	client := New(&Configuration{
+		Addr:     server.URL,
+		Recorder: recorder,
+	})
+	received := make(chan struct{}, 1)
+	client.On("trades.BTC-PERPETUAL.raw", func(e *models.TradesNotification) {
+		received <- struct{}{}
+	})
+	client.Subscribe([]string{"trades.BTC-PERPETUAL.raw"})
+	server.NotifyRaw("trades.BTC-PERPETUAL.raw", `[{"trade_seq":1,"instrument_name":"BTC-PERPETUAL","price":6000,"amount":10}]`)
+	server.NotifyRaw("trades.BTC-PERPETUAL.raw", `[{"trade_seq":2,"instrument_name":"BTC-PERPETUAL","price":6001,"amount":20}]`)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatal("no trades notification")
+		}
+	}
+	assert.Nil(t, recorder.Close())
+
+	replayer, err := NewReplayer(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+	offline := New(&Configuration{Addr: server.HTTPURL})
+	var seqs []int
+	offline.On("trades.BTC-PERPETUAL.raw", func(e *models.TradesNotification) {
+		seqs = append(seqs, (*e)[0].TradeSeq)
+	})
+	assert.Nil(t, replayer.Play(context.Background(), offline, 0))
+	assert.Equal(t, []int{1, 2}, seqs)
+
+	replayer, _ = NewReplayer(bytes.NewReader(buf.Bytes()))
+	var in, out int
+	for {
+		frame, err := replayer.Next()
+		if err != nil {
+			break
+		}
+		if frame.Dir == FrameIn {
+			in++
+		} else {
+			out++
+		}
+	}
+	assert.True(t, out >= 2)
+	assert.True(t, in >= out+2)
+}
+
+func TestRecorder_Redact(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+	server.SetCredentials("key", "s3cr3t-value")
+
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	client := New(&Configuration{
+		Addr:      server.URL,
+		ApiKey:    "key",
+		SecretKey: "s3cr3t-value",
+		Recorder:  recorder,
+	})
+	assert.NotEmpty(t, client.auth.token)
+	assert.Nil(t, recorder.Close())
+
+	gz, err := gzip.NewReader(&buf)
+	assert.Nil(t, err)
+	recording, err := ioutil.ReadAll(gz)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(recording), "s3cr3t-value")
+	assert.NotContains(t, string(recording), client.auth.token)
+	assert.NotContains(t, string(recording), client.auth.refresh)
+	assert.Contains(t, string(recording), Redacted)
+	assert.Contains(t, string(recording), "public/auth")
+}
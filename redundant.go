@@ -0,0 +1,256 @@
+package deribit
+
+import (
+	"encoding/json"
+	"github.com/chuckpreslar/emission"
+	"github.com/frankrap/deribit-api/models"
+	"hash/fnv"
+	"sync"
+)
+
+const (
+	// redundantRecentHashes is the number of message hashes remembered per channel
+	// for notifications without a sequence number
+	redundantRecentHashes = 64
+	// redundantOrdersLimit is the number of orders remembered before old ones are pruned
+	redundantOrdersLimit = 4096
+	// redundantOrdersWindow is how long (ms) an order update is remembered after pruning
+	redundantOrdersWindow = 10 * 60 * 1000
+)
+
+// RedundantFeed subscribes the same channels on several connections and emits
+// every notification once, from whichever connection delivers it first.
+// Duplicates are detected by change_id for books, trade_seq for trades,
+// last_update_timestamp, filled_amount and order_state for orders and by content
+// for other channels.
+type RedundantFeed struct {
+	clients []*Client
+	emitter *emission.Emitter
+
+	mu       sync.Mutex
+	channels map[string]struct{}
+
+	emitMu sync.Mutex
+	state  map[string]*redundantState
+}
+
+// redundantState is the deduplication state of a channel
+type redundantState struct {
+	changeID  int64
+	tradeSeqs map[string]int
+	orders    map[string]*orderVersions
+	newest    int64
+	hashes    []uint64
+	next      int
+}
+
+// NewRedundantFeed creates a RedundantFeed over n connections configured by cfg
+func NewRedundantFeed(cfg *Configuration, n int) *RedundantFeed {
+	if n <= 0 {
+		n = 2
+	}
+	var clients []*Client
+	for i := 0; i < n; i++ {
+		clients = append(clients, New(cfg))
+	}
+	return NewRedundantFeedFromClients(clients...)
+}
+
+// NewRedundantFeedFromClients creates a RedundantFeed over existing connections
+func NewRedundantFeedFromClients(clients ...*Client) *RedundantFeed {
+	return &RedundantFeed{
+		clients:  clients,
+		emitter:  emission.NewEmitter(),
+		channels: make(map[string]struct{}),
+		state:    make(map[string]*redundantState),
+	}
+}
+
+// Clients returns the underlying connections
+func (f *RedundantFeed) Clients() []*Client {
+	return f.clients
+}
+
+// On adds a listener to a specific event
+func (f *RedundantFeed) On(event interface{}, listener interface{}) *emission.Emitter {
+	return f.emitter.On(event, listener)
+}
+
+// Off removes a listener for an event
+func (f *RedundantFeed) Off(event interface{}, listener interface{}) *emission.Emitter {
+	return f.emitter.Off(event, listener)
+}
+
+// Subscribe subscribes channels on every connection
+func (f *RedundantFeed) Subscribe(channels []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var added []string
+	for _, channel := range channels {
+		if _, ok := f.channels[channel]; ok {
+			continue
+		}
+		f.channels[channel] = struct{}{}
+		added = append(added, channel)
+		for _, client := range f.clients {
+			client.On(channel, f.listener(channel))
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	for _, client := range f.clients {
+		client.Subscribe(added)
+	}
+}
+
+func (f *RedundantFeed) listener(channel string) func(notification interface{}) {
+	return func(notification interface{}) {
+		f.emitMu.Lock()
+		defer f.emitMu.Unlock()
+
+		state, ok := f.state[channel]
+		if !ok {
+			state = &redundantState{
+				tradeSeqs: make(map[string]int),
+				orders:    make(map[string]*orderVersions),
+			}
+			f.state[channel] = state
+		}
+		if notification, ok := state.filter(notification); ok {
+			f.emitter.Emit(channel, notification)
+		}
+	}
+}
+
+// filter returns the part of notification not seen before, and false if there is none
+func (s *redundantState) filter(notification interface{}) (interface{}, bool) {
+	switch n := notification.(type) {
+	case *models.OrderBookRawNotification:
+		return n, s.newChangeID(n.ChangeID)
+	case *models.OrderBookNotification:
+		return n, s.newChangeID(n.ChangeID)
+	case *models.OrderBookGroupNotification:
+		return n, s.newChangeID(n.ChangeID)
+	case *models.TradesNotification:
+		var result models.TradesNotification
+		for _, trade := range *n {
+			if s.newTrade(trade.InstrumentName, trade.TradeSeq) {
+				result = append(result, trade)
+			}
+		}
+		return &result, len(result) > 0
+	case *models.UserTradesNotification:
+		result := models.UserTradesNotification(s.newUserTrades(*n))
+		return &result, len(result) > 0
+	case *models.UserOrderNotification:
+		result := models.UserOrderNotification(s.newOrders(*n))
+		return &result, len(result) > 0
+	case *models.UserChangesNotification:
+		result := models.UserChangesNotification{
+			Trades:    s.newUserTrades(n.Trades),
+			Positions: n.Positions,
+			Orders:    s.newOrders(n.Orders),
+		}
+		return &result, len(result.Trades) > 0 || len(result.Orders) > 0
+	default:
+		return n, s.newContent(n)
+	}
+}
+
+func (s *redundantState) newChangeID(changeID int64) bool {
+	if changeID <= s.changeID {
+		return false
+	}
+	s.changeID = changeID
+	return true
+}
+
+func (s *redundantState) newTrade(instrumentName string, tradeSeq int) bool {
+	if tradeSeq <= s.tradeSeqs[instrumentName] {
+		return false
+	}
+	s.tradeSeqs[instrumentName] = tradeSeq
+	return true
+}
+
+func (s *redundantState) newUserTrades(trades []models.UserTrade) (result []models.UserTrade) {
+	for _, trade := range trades {
+		if s.newTrade(trade.InstrumentName, trade.TradeSeq) {
+			result = append(result, trade)
+		}
+	}
+	return
+}
+
+// orderVersions are the updates of an order seen at its newest last_update_timestamp,
+// an order can change more than once in a millisecond, e.g. filled then cancelled
+type orderVersions struct {
+	timestamp int64
+	versions  []orderVersion
+}
+
+type orderVersion struct {
+	filledAmount float64
+	orderState   string
+}
+
+func (s *redundantState) newOrders(orders []models.Order) (result []models.Order) {
+	for _, order := range orders {
+		version := orderVersion{filledAmount: order.FilledAmount, orderState: order.OrderState}
+		seen, ok := s.orders[order.OrderID]
+		if ok && (order.LastUpdateTimestamp < seen.timestamp ||
+			order.LastUpdateTimestamp == seen.timestamp && seen.has(version)) {
+			continue
+		}
+		if !ok || order.LastUpdateTimestamp > seen.timestamp {
+			seen = &orderVersions{timestamp: order.LastUpdateTimestamp}
+			s.orders[order.OrderID] = seen
+		}
+		seen.versions = append(seen.versions, version)
+		if order.LastUpdateTimestamp > s.newest {
+			s.newest = order.LastUpdateTimestamp
+		}
+		result = append(result, order)
+	}
+	if len(s.orders) > redundantOrdersLimit {
+		for id, seen := range s.orders {
+			if seen.timestamp < s.newest-redundantOrdersWindow {
+				delete(s.orders, id)
+			}
+		}
+	}
+	return
+}
+
+func (v *orderVersions) has(version orderVersion) bool {
+	for _, seen := range v.versions {
+		if seen == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *redundantState) newContent(notification interface{}) bool {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write(data)
+	sum := h.Sum64()
+	for _, v := range s.hashes {
+		if v == sum {
+			return false
+		}
+	}
+	if len(s.hashes) < redundantRecentHashes {
+		s.hashes = append(s.hashes, sum)
+	} else {
+		s.hashes[s.next] = sum
+		s.next = (s.next + 1) % redundantRecentHashes
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package deribit
+
+import (
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRedundantFeed_Dedup(t *testing.T) {
+	feed := NewRedundantFeedFromClients()
+
+	var books []int64
+	feed.On("book.BTC-PERPETUAL.raw", func(e *models.OrderBookRawNotification) {
+		books = append(books, e.ChangeID)
+	})
+	var trades []int
+	feed.On("trades.BTC-PERPETUAL.raw", func(e *models.TradesNotification) {
+		for _, v := range *e {
+			trades = append(trades, v.TradeSeq)
+		}
+	})
+	var orders []string
+	feed.On("user.orders.BTC-PERPETUAL.raw", func(e *models.UserOrderNotification) {
+		for _, v := range *e {
+			orders = append(orders, v.OrderState)
+		}
+	})
+	var tickers int
+	feed.On("ticker.BTC-PERPETUAL.raw", func(e *models.TickerNotification) {
+		tickers++
+	})
+
+	book := feed.listener("book.BTC-PERPETUAL.raw")
+	book(&models.OrderBookRawNotification{ChangeID: 1})
+	book(&models.OrderBookRawNotification{ChangeID: 2})
+	book(&models.OrderBookRawNotification{ChangeID: 1})
+	book(&models.OrderBookRawNotification{ChangeID: 2})
+	book(&models.OrderBookRawNotification{ChangeID: 3})
+	assert.Equal(t, []int64{1, 2, 3}, books)
+
+	trade := feed.listener("trades.BTC-PERPETUAL.raw")
+	trade(&models.TradesNotification{{InstrumentName: "BTC-PERPETUAL", TradeSeq: 10}})
+	trade(&models.TradesNotification{{InstrumentName: "BTC-PERPETUAL", TradeSeq: 10}, {InstrumentName: "BTC-PERPETUAL", TradeSeq: 11}})
+	trade(&models.TradesNotification{{InstrumentName: "BTC-PERPETUAL", TradeSeq: 11}})
+	assert.Equal(t, []int{10, 11}, trades)
+
+	order := feed.listener("user.orders.BTC-PERPETUAL.raw")
+	order(&models.UserOrderNotification{{OrderID: "1", OrderState: "open", LastUpdateTimestamp: 100}})
+	order(&models.UserOrderNotification{{OrderID: "1", OrderState: "open", LastUpdateTimestamp: 100}})
+	order(&models.UserOrderNotification{{OrderID: "1", OrderState: "filled", LastUpdateTimestamp: 101}})
+	order(&models.UserOrderNotification{{OrderID: "1", OrderState: "open", LastUpdateTimestamp: 100}})
+	// a partial fill and a cancel in the same millisecond, delivered by both connections
+	order(&models.UserOrderNotification{{OrderID: "2", OrderState: "open", FilledAmount: 5, LastUpdateTimestamp: 200}})
+	order(&models.UserOrderNotification{{OrderID: "2", OrderState: "cancelled", FilledAmount: 5, LastUpdateTimestamp: 200}})
+	order(&models.UserOrderNotification{{OrderID: "2", OrderState: "open", FilledAmount: 5, LastUpdateTimestamp: 200}})
+	order(&models.UserOrderNotification{{OrderID: "2", OrderState: "cancelled", FilledAmount: 5, LastUpdateTimestamp: 200}})
+	assert.Equal(t, []string{"open", "filled", "open", "cancelled"}, orders)
+
+	ticker := feed.listener("ticker.BTC-PERPETUAL.raw")
+	ticker(&models.TickerNotification{Timestamp: 1, LastPrice: 6000})
+	ticker(&models.TickerNotification{Timestamp: 1, LastPrice: 6000})
+	ticker(&models.TickerNotification{Timestamp: 2, LastPrice: 6000})
+	assert.Equal(t, 2, tickers)
+}
@@ -0,0 +1,344 @@
+package deribit
+
+import (
+	"github.com/frankrap/deribit-api/models"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultRegistryRefresh is the reload interval of an InstrumentRegistry started without one
+const DefaultRegistryRefresh = time.Hour
+
+// instrumentStateChannel notifies the state changes of every instrument
+const instrumentStateChannel = "instrument.state.any.any"
+
+// InstrumentRegistry caches the instruments of all currencies and kinds, indexed by
+// name, kind, expiry and strike. Once started it reloads on instrument.state
+// notifications and on a timer. Lookups never return expired instruments.
+// Lookups by currency match the base currency of instruments and the currency they
+// were loaded with, e.g. BTC and USDC for BTC_USDC-PERPETUAL.
+type InstrumentRegistry struct {
+	client     *Client
+	currencies []string
+
+	mu        sync.RWMutex
+	index     *instrumentIndex
+	stop      chan struct{}
+	listening bool
+	reload    chan struct{}
+	now       func() time.Time
+}
+
+// instrumentIndex is an immutable index of instruments, replaced on every change
+type instrumentIndex struct {
+	byName map[string]models.Instrument
+	// requested is the currency instruments were loaded with, by name, if not their base currency
+	requested map[string]string
+	// byCurrency and byKind, by currency and kind, are sorted by expiry, strike and name
+	byCurrency map[string][]models.Instrument
+	byKind     map[string][]models.Instrument
+}
+
+// NewInstrumentRegistry creates an InstrumentRegistry of the instruments of currencies,
+// all currencies if none
+func NewInstrumentRegistry(client *Client, currencies ...string) *InstrumentRegistry {
+	return &InstrumentRegistry{
+		client:     client,
+		currencies: currencies,
+		index:      newInstrumentIndex(nil, nil),
+		reload:     make(chan struct{}, 1),
+		now:        time.Now,
+	}
+}
+
+// Load fetches the instruments of every kind of the currencies of the registry
+func (r *InstrumentRegistry) Load() error {
+	currencies := r.currencies
+	if len(currencies) == 0 {
+		all, err := r.client.GetCurrencies()
+		if err != nil {
+			return err
+		}
+		for _, currency := range all {
+			currencies = append(currencies, currency.Currency)
+		}
+	}
+	var instruments []models.Instrument
+	requested := make(map[string]string)
+	for _, currency := range currencies {
+		result, err := r.client.GetInstruments(&models.GetInstrumentsParams{Currency: currency})
+		if err != nil {
+			return err
+		}
+		for _, instrument := range result {
+			if instrument.BaseCurrency != currency {
+				requested[instrument.InstrumentName] = currency
+			}
+		}
+		instruments = append(instruments, result...)
+	}
+	r.setIndex(r.unexpired(instruments), requested)
+	return nil
+}
+
+// Start subscribes to instrument state notifications and reloads the registry
+// on them and every interval until Stop
+func (r *InstrumentRegistry) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRegistryRefresh
+	}
+	r.mu.Lock()
+	if r.stop != nil {
+		r.mu.Unlock()
+		return
+	}
+	r.stop = make(chan struct{})
+	stop := r.stop
+	listening := r.listening
+	r.listening = true
+	r.mu.Unlock()
+
+	if !listening {
+		// emission removes listeners by code pointer, so the listener stays
+		// registered and ignores notifications while stopped
+		r.client.On(instrumentStateChannel, r.onState)
+		r.client.Subscribe([]string{instrumentStateChannel})
+	}
+	go r.refresh(stop, interval)
+}
+
+// Stop stops reloading the registry
+func (r *InstrumentRegistry) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	r.stop = nil
+}
+
+func (r *InstrumentRegistry) onState(e *models.InstrumentStateNotification) {
+	r.mu.RLock()
+	running := r.stop != nil
+	r.mu.RUnlock()
+	if !running {
+		return
+	}
+	switch e.State {
+	case models.InstrumentStateSettled, models.InstrumentStateClosed, models.InstrumentStateTerminated:
+		r.remove(e.InstrumentName)
+	default:
+		// calls from a listener would block the connection, reload in refresh
+		select {
+		case r.reload <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (r *InstrumentRegistry) refresh(stop chan struct{}, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-r.reload:
+		case <-stop:
+			return
+		}
+		if err := r.Load(); err != nil {
+			log.Printf("instrument registry: %v", err)
+		}
+	}
+}
+
+func (r *InstrumentRegistry) remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.index.byName[name]; !ok {
+		return
+	}
+	var instruments []models.Instrument
+	for _, instrument := range r.index.byName {
+		if instrument.InstrumentName != name {
+			instruments = append(instruments, instrument)
+		}
+	}
+	r.index = newInstrumentIndex(instruments, r.index.requested)
+}
+
+func (r *InstrumentRegistry) setIndex(instruments []models.Instrument, requested map[string]string) {
+	index := newInstrumentIndex(instruments, requested)
+	r.mu.Lock()
+	r.index = index
+	r.mu.Unlock()
+}
+
+func (r *InstrumentRegistry) getIndex() *instrumentIndex {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.index
+}
+
+// unexpired returns the instruments of instruments which have not expired
+func (r *InstrumentRegistry) unexpired(instruments []models.Instrument) []models.Instrument {
+	now := millis(r.now())
+	var result []models.Instrument
+	for _, instrument := range instruments {
+		if instrument.ExpirationTimestamp > now {
+			result = append(result, instrument)
+		}
+	}
+	return result
+}
+
+// Instrument returns the instrument named name
+func (r *InstrumentRegistry) Instrument(name string) (models.Instrument, bool) {
+	instrument, ok := r.getIndex().byName[name]
+	if !ok || instrument.ExpirationTimestamp <= millis(r.now()) {
+		return models.Instrument{}, false
+	}
+	return instrument, true
+}
+
+// Instruments returns the instruments of kind of currency sorted by expiry, strike and name,
+// an empty kind means every kind
+func (r *InstrumentRegistry) Instruments(currency string, kind models.Kind) []models.Instrument {
+	index := r.getIndex()
+	if kind == "" {
+		return r.unexpired(index.byCurrency[currency])
+	}
+	return r.unexpired(index.byKind[currency+"/"+string(kind)])
+}
+
+// Expiries returns the expiries of the instruments of kind of currency in ascending order
+func (r *InstrumentRegistry) Expiries(currency string, kind models.Kind) []time.Time {
+	var expiries []time.Time
+	var last int64
+	for _, instrument := range r.Instruments(currency, kind) {
+		if instrument.ExpirationTimestamp != last {
+			last = instrument.ExpirationTimestamp
+			expiries = append(expiries, msTime(last))
+		}
+	}
+	return expiries
+}
+
+// Expiring returns the instruments of kind of currency which expire at expiry
+func (r *InstrumentRegistry) Expiring(currency string, kind models.Kind, expiry time.Time) []models.Instrument {
+	ts := millis(expiry)
+	return r.filter(currency, kind, func(instrument *models.Instrument) bool {
+		return instrument.ExpirationTimestamp == ts
+	})
+}
+
+// ExpiringOn returns the instruments of kind of currency which expire on the UTC date of day,
+// e.g. all ETH options expiring Friday
+func (r *InstrumentRegistry) ExpiringOn(currency string, kind models.Kind, day time.Time) []models.Instrument {
+	y, m, d := day.UTC().Date()
+	return r.filter(currency, kind, func(instrument *models.Instrument) bool {
+		ey, em, ed := msTime(instrument.ExpirationTimestamp).UTC().Date()
+		return ey == y && em == m && ed == d
+	})
+}
+
+// Strikes returns the strikes of the options of currency expiring at expiry in ascending order
+func (r *InstrumentRegistry) Strikes(currency string, expiry time.Time) []float64 {
+	var strikes []float64
+	for _, instrument := range r.Expiring(currency, models.KindOption, expiry) {
+		if n := len(strikes); n == 0 || strikes[n-1] != instrument.Strike {
+			strikes = append(strikes, instrument.Strike)
+		}
+	}
+	return strikes
+}
+
+// Option returns the option of currency of optionType, "call" or "put", expiring at expiry with strike
+func (r *InstrumentRegistry) Option(currency string, expiry time.Time, strike float64, optionType string) (models.Instrument, bool) {
+	for _, instrument := range r.Expiring(currency, models.KindOption, expiry) {
+		if instrument.Strike == strike && instrument.OptionType == optionType {
+			return instrument, true
+		}
+	}
+	return models.Instrument{}, false
+}
+
+// Front returns the instrument of kind of currency expiring first,
+// restricted to settlementPeriod unless empty. Perpetuals only match
+// settlementPeriod "perpetual".
+func (r *InstrumentRegistry) Front(currency string, kind models.Kind, settlementPeriod string) (models.Instrument, bool) {
+	for _, instrument := range r.Instruments(currency, kind) {
+		if settlementPeriod == "" && instrument.SettlementPeriod != models.SettlementPeriodPerpetual ||
+			instrument.SettlementPeriod == settlementPeriod {
+			return instrument, true
+		}
+	}
+	return models.Instrument{}, false
+}
+
+// FrontMonthFuture returns the monthly future of currency expiring first
+func (r *InstrumentRegistry) FrontMonthFuture(currency string) (models.Instrument, bool) {
+	return r.Front(currency, models.KindFuture, models.SettlementPeriodMonth)
+}
+
+func (r *InstrumentRegistry) filter(currency string, kind models.Kind, match func(*models.Instrument) bool) []models.Instrument {
+	var result []models.Instrument
+	for _, instrument := range r.Instruments(currency, kind) {
+		if match(&instrument) {
+			result = append(result, instrument)
+		}
+	}
+	return result
+}
+
+func newInstrumentIndex(instruments []models.Instrument, requested map[string]string) *instrumentIndex {
+	index := &instrumentIndex{
+		byName:     make(map[string]models.Instrument, len(instruments)),
+		requested:  requested,
+		byCurrency: make(map[string][]models.Instrument),
+		byKind:     make(map[string][]models.Instrument),
+	}
+	for _, instrument := range instruments {
+		index.byName[instrument.InstrumentName] = instrument
+		currencies := []string{instrument.BaseCurrency}
+		if currency, ok := requested[instrument.InstrumentName]; ok {
+			currencies = append(currencies, currency)
+		}
+		for _, currency := range currencies {
+			index.byCurrency[currency] = append(index.byCurrency[currency], instrument)
+			key := currency + "/" + string(instrument.Kind)
+			index.byKind[key] = append(index.byKind[key], instrument)
+		}
+	}
+	for _, instruments := range index.byCurrency {
+		sortInstruments(instruments)
+	}
+	for _, instruments := range index.byKind {
+		sortInstruments(instruments)
+	}
+	return index
+}
+
+// sortInstruments sorts instruments by expiry, strike and name
+func sortInstruments(instruments []models.Instrument) {
+	sort.Slice(instruments, func(i, j int) bool {
+		a, b := &instruments[i], &instruments[j]
+		if a.ExpirationTimestamp != b.ExpirationTimestamp {
+			return a.ExpirationTimestamp < b.ExpirationTimestamp
+		}
+		if a.Strike != b.Strike {
+			return a.Strike < b.Strike
+		}
+		return a.InstrumentName < b.InstrumentName
+	})
+}
+
+// msTime returns the time of ts in milliseconds since epoch
+func msTime(ts int64) time.Time {
+	return time.Unix(0, ts*int64(time.Millisecond))
+}
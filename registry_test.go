@@ -0,0 +1,102 @@
+package deribit
+
+import (
+	"encoding/json"
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInstrumentRegistry(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	day := int64(24 * time.Hour / time.Millisecond)
+	now := time.Date(2020, 5, 4, 12, 0, 0, 0, time.UTC) // Monday
+	friday := millis(time.Date(2020, 5, 8, 8, 0, 0, 0, time.UTC))
+	month := millis(time.Date(2020, 5, 29, 8, 0, 0, 0, time.UTC))
+	var mu sync.Mutex
+	instruments := map[string][]models.Instrument{
+		"BTC": {
+			{InstrumentName: "BTC-PERPETUAL", Kind: "future", BaseCurrency: "BTC", SettlementPeriod: "perpetual", ExpirationTimestamp: 32503708800000},
+			{InstrumentName: "BTC-29MAY20", Kind: "future", BaseCurrency: "BTC", SettlementPeriod: "month", ExpirationTimestamp: month},
+			{InstrumentName: "BTC-8MAY20", Kind: "future", BaseCurrency: "BTC", SettlementPeriod: "week", ExpirationTimestamp: friday},
+			{InstrumentName: "BTC-1MAY20", Kind: "future", BaseCurrency: "BTC", SettlementPeriod: "week", ExpirationTimestamp: friday - 7*day},
+		},
+		"ETH": {
+			{InstrumentName: "ETH-8MAY20-200-C", Kind: "option", BaseCurrency: "ETH", OptionType: "call", Strike: 200, ExpirationTimestamp: friday},
+			{InstrumentName: "ETH-8MAY20-150-P", Kind: "option", BaseCurrency: "ETH", OptionType: "put", Strike: 150, ExpirationTimestamp: friday},
+			{InstrumentName: "ETH-8MAY20-150-C", Kind: "option", BaseCurrency: "ETH", OptionType: "call", Strike: 150, ExpirationTimestamp: friday},
+			{InstrumentName: "ETH-29MAY20-200-C", Kind: "option", BaseCurrency: "ETH", OptionType: "call", Strike: 200, ExpirationTimestamp: month},
+		},
+		"USDC": {
+			{InstrumentName: "BTC_USDC-PERPETUAL", Kind: "future", BaseCurrency: "BTC", SettlementCurrency: "USDC", SettlementPeriod: "perpetual", ExpirationTimestamp: 32503708800000},
+			{InstrumentName: "BTC_USDC-29MAY20", Kind: "future", BaseCurrency: "BTC", SettlementCurrency: "USDC", SettlementPeriod: "month", ExpirationTimestamp: month},
+		},
+	}
+	server.Respond("public/get_currencies", []models.Currency{{Currency: "BTC"}, {Currency: "ETH"}, {Currency: "USDC"}})
+	server.Handle("public/get_instruments", func(conn *deribittest.Conn, params json.RawMessage) (interface{}, error) {
+		var p models.GetInstrumentsParams
+		json.Unmarshal(params, &p)
+		mu.Lock()
+		defer mu.Unlock()
+		return instruments[p.Currency], nil
+	})
+
+	client := newMockClient(server)
+	registry := NewInstrumentRegistry(client)
+	registry.now = func() time.Time { return now }
+	assert.Nil(t, registry.Load())
+
+	_, ok := registry.Instrument("BTC-1MAY20")
+	assert.False(t, ok)
+	future, ok := registry.FrontMonthFuture("BTC")
+	assert.True(t, ok)
+	assert.Equal(t, "BTC-29MAY20", future.InstrumentName)
+	future, ok = registry.Front("BTC", models.KindFuture, "")
+	assert.True(t, ok)
+	assert.Equal(t, "BTC-8MAY20", future.InstrumentName)
+
+	options := registry.ExpiringOn("ETH", models.KindOption, time.Date(2020, 5, 8, 0, 0, 0, 0, time.UTC))
+	var names []string
+	for _, option := range options {
+		names = append(names, option.InstrumentName)
+	}
+	assert.Equal(t, []string{"ETH-8MAY20-150-C", "ETH-8MAY20-150-P", "ETH-8MAY20-200-C"}, names)
+	assert.Equal(t, []float64{150, 200}, registry.Strikes("ETH", msTime(friday)))
+	assert.Len(t, registry.Expiries("ETH", ""), 2)
+	put, ok := registry.Option("ETH", msTime(friday), 150, models.OptionTypePut)
+	assert.True(t, ok)
+	assert.Equal(t, "ETH-8MAY20-150-P", put.InstrumentName)
+
+	// linear instruments are found by their base currency and the currency they were loaded with
+	future, ok = registry.FrontMonthFuture("USDC")
+	assert.True(t, ok)
+	assert.Equal(t, "BTC_USDC-29MAY20", future.InstrumentName)
+	assert.Len(t, registry.Instruments("USDC", models.KindFuture), 2)
+	assert.Len(t, registry.Expiries("USDC", ""), 2)
+	assert.Len(t, registry.Instruments("BTC", models.KindFuture), 5)
+
+	registry.Start(time.Hour)
+	defer registry.Stop()
+	assert.True(t, server.WaitSubscribed(instrumentStateChannel, time.Second))
+
+	server.Notify(instrumentStateChannel, models.InstrumentStateNotification{InstrumentName: "BTC-8MAY20", State: "terminated"})
+	assert.Eventually(t, func() bool {
+		_, ok := registry.Instrument("BTC-8MAY20")
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	instruments["BTC"] = append(instruments["BTC"], models.Instrument{InstrumentName: "BTC-26JUN20", Kind: "future", BaseCurrency: "BTC", SettlementPeriod: "month", ExpirationTimestamp: month + 28*day})
+	mu.Unlock()
+	server.Notify(instrumentStateChannel, models.InstrumentStateNotification{InstrumentName: "BTC-26JUN20", State: "created"})
+
+	assert.Eventually(t, func() bool {
+		_, ok := registry.Instrument("BTC-26JUN20")
+		return ok
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,192 @@
+package deribit
+
+import (
+	"fmt"
+	"github.com/frankrap/deribit-api/models"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rounding is how prices and amounts are rounded to the instrument steps
+type Rounding int
+
+const (
+	// RoundNearest rounds to the nearest step
+	RoundNearest Rounding = iota
+	// RoundFloor rounds down
+	RoundFloor
+	// RoundCeil rounds up
+	RoundCeil
+	// RoundPassive rounds buy prices down and sell prices up, away from the other side of the book
+	RoundPassive
+	// RoundAggressive rounds buy prices up and sell prices down, towards the other side of the book
+	RoundAggressive
+)
+
+// stepEpsilon is the relative tolerance of a value to a multiple of a step
+const stepEpsilon = 1e-9
+
+// PriceStep returns the price step of instrument, its tick size
+func PriceStep(instrument *models.Instrument) float64 {
+	return instrument.TickSize
+}
+
+// PriceStepAt returns the tick size of instrument at price, the tick size of the
+// highest tick size step price is above if any
+func PriceStepAt(instrument *models.Instrument, price float64) float64 {
+	step := instrument.TickSize
+	for _, s := range instrument.TickSizeSteps {
+		if price > s.AbovePrice {
+			step = s.TickSize
+		}
+	}
+	return step
+}
+
+// AmountStep returns the amount step of instrument, its min trade amount or else its contract size
+func AmountStep(instrument *models.Instrument) float64 {
+	if instrument.MinTradeAmount > 0 {
+		return instrument.MinTradeAmount
+	}
+	return instrument.ContractSize
+}
+
+// RoundPrice rounds price of an order of direction to the tick size of instrument
+func RoundPrice(instrument *models.Instrument, direction models.Direction, price float64, rounding Rounding) float64 {
+	switch rounding {
+	case RoundPassive:
+		rounding = RoundFloor
+		if direction == models.DirectionSell {
+			rounding = RoundCeil
+		}
+	case RoundAggressive:
+		rounding = RoundCeil
+		if direction == models.DirectionSell {
+			rounding = RoundFloor
+		}
+	}
+	return roundToStep(price, PriceStepAt(instrument, price), rounding)
+}
+
+// RoundAmount rounds amount to the amount step of instrument,
+// the side aware roundings round down
+func RoundAmount(instrument *models.Instrument, amount float64, rounding Rounding) float64 {
+	if rounding == RoundPassive || rounding == RoundAggressive {
+		rounding = RoundFloor
+	}
+	return roundToStep(amount, AmountStep(instrument), rounding)
+}
+
+// ValidatePrice returns an error if price is not a multiple of the tick size of instrument
+func ValidatePrice(instrument *models.Instrument, price float64) error {
+	if step := PriceStepAt(instrument, price); !isMultiple(price, step) {
+		return fmt.Errorf("price %v of %v is not a multiple of the tick size %v", price, instrument.InstrumentName, step)
+	}
+	return nil
+}
+
+// ValidateAmount returns an error if amount is not a positive multiple of the amount step of instrument
+func ValidateAmount(instrument *models.Instrument, amount float64) error {
+	step := AmountStep(instrument)
+	if amount <= 0 || !isMultiple(amount, step) {
+		return fmt.Errorf("amount %v of %v is not a positive multiple of %v", amount, instrument.InstrumentName, step)
+	}
+	return nil
+}
+
+// roundToStep rounds value to a multiple of step, a step of 0 leaves value unchanged
+func roundToStep(value float64, step float64, rounding Rounding) float64 {
+	if step <= 0 {
+		return value
+	}
+	n := value / step
+	switch rounding {
+	case RoundFloor:
+		n = math.Floor(n + stepEpsilon)
+	case RoundCeil:
+		n = math.Ceil(n - stepEpsilon)
+	default:
+		n = math.Round(n)
+	}
+	// drop the floating point noise of the multiplication, e.g. 3*0.1
+	scale := math.Pow10(decimals(step))
+	return math.Round(n*step*scale) / scale
+}
+
+// isMultiple reports whether value is a multiple of step
+func isMultiple(value float64, step float64) bool {
+	if step <= 0 {
+		return true
+	}
+	n := value / step
+	return math.Abs(n-math.Round(n)) < stepEpsilon*math.Max(1, math.Abs(n))
+}
+
+// decimals returns the number of decimals of step
+func decimals(step float64) int {
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// SetAutoRounding rounds the prices and amounts of buy and sell orders sent by Call
+// to the steps of their instrument in registry, nil disables the rounding.
+// Orders of instruments missing from registry and edits are sent unchanged.
+func (c *Client) SetAutoRounding(registry *InstrumentRegistry, rounding Rounding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rounding.registry = registry
+	c.rounding.rounding = rounding
+}
+
+// roundOrder returns a copy of the params of an order with the price and amount rounded
+// if auto rounding is set, or an error if the amount rounds to zero
+func (c *Client) roundOrder(params interface{}) (interface{}, error) {
+	c.mu.RLock()
+	registry, rounding := c.rounding.registry, c.rounding.rounding
+	c.mu.RUnlock()
+	if registry == nil {
+		return params, nil
+	}
+
+	switch p := params.(type) {
+	case *models.BuyParams:
+		instrument, ok := registry.Instrument(p.InstrumentName)
+		if !ok {
+			return params, nil
+		}
+		rounded := *p
+		var err error
+		rounded.Price, rounded.StopPrice, rounded.Amount, err = roundOrderValues(&instrument, models.DirectionBuy, p.Price, p.StopPrice, p.Amount, rounding)
+		return &rounded, err
+	case *models.SellParams:
+		instrument, ok := registry.Instrument(p.InstrumentName)
+		if !ok {
+			return params, nil
+		}
+		rounded := *p
+		var err error
+		rounded.Price, rounded.StopPrice, rounded.Amount, err = roundOrderValues(&instrument, models.DirectionSell, p.Price, p.StopPrice, p.Amount, rounding)
+		return &rounded, err
+	}
+	return params, nil
+}
+
+func roundOrderValues(instrument *models.Instrument, direction models.Direction, price float64, stopPrice float64, amount float64, rounding Rounding) (float64, float64, float64, error) {
+	// zero prices are omitted, e.g. of market orders
+	if price != 0 {
+		price = RoundPrice(instrument, direction, price, rounding)
+	}
+	if stopPrice != 0 {
+		stopPrice = RoundPrice(instrument, direction, stopPrice, rounding)
+	}
+	rounded := RoundAmount(instrument, amount, rounding)
+	if rounded <= 0 {
+		return 0, 0, 0, fmt.Errorf("amount %v of %v rounds to %v, below the amount step %v", amount, instrument.InstrumentName, rounded, AmountStep(instrument))
+	}
+	return price, stopPrice, rounded, nil
+}
@@ -0,0 +1,69 @@
+package deribit
+
+import (
+	"github.com/frankrap/deribit-api/deribittest"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRounding(t *testing.T) {
+	perpetual := &models.Instrument{InstrumentName: "BTC-PERPETUAL", TickSize: 0.5, MinTradeAmount: 10, ContractSize: 10}
+	option := &models.Instrument{InstrumentName: "BTC-8MAY20-9000-C", TickSize: 0.0005, MinTradeAmount: 0.1, ContractSize: 1}
+
+	assert.Equal(t, 6000.5, RoundPrice(perpetual, models.DirectionBuy, 6000.7, RoundNearest))
+	assert.Equal(t, 6000.5, RoundPrice(perpetual, models.DirectionBuy, 6000.7, RoundPassive))
+	assert.Equal(t, 6001.0, RoundPrice(perpetual, models.DirectionSell, 6000.7, RoundPassive))
+	assert.Equal(t, 6001.0, RoundPrice(perpetual, models.DirectionBuy, 6000.7, RoundAggressive))
+	assert.Equal(t, 6000.5, RoundPrice(perpetual, models.DirectionSell, 6000.7, RoundAggressive))
+	assert.Equal(t, 0.0125, RoundPrice(option, models.DirectionBuy, 0.01268, RoundFloor))
+	assert.Equal(t, 0.013, RoundPrice(option, models.DirectionBuy, 0.01251, RoundCeil))
+	// multiples stay unchanged despite floating point noise
+	assert.Equal(t, 0.3, RoundAmount(option, 0.1*3, RoundCeil))
+	assert.Equal(t, 0.3, RoundAmount(option, 0.1*3, RoundFloor))
+	assert.Equal(t, 120.0, RoundAmount(perpetual, 125, RoundPassive))
+
+	option.TickSizeSteps = []models.TickSizeStep{{AbovePrice: 0.005, TickSize: 0.0005}}
+	option.TickSize = 0.0001
+	assert.Equal(t, 0.0001, PriceStep(option))
+	assert.Equal(t, 0.0001, PriceStepAt(option, 0.004))
+	assert.Equal(t, 0.0005, PriceStepAt(option, 0.0125))
+	assert.Equal(t, 0.0042, RoundPrice(option, models.DirectionBuy, 0.00424, RoundNearest))
+	assert.Equal(t, 0.0125, RoundPrice(option, models.DirectionBuy, 0.01268, RoundFloor))
+	assert.Nil(t, ValidatePrice(option, 0.0125))
+	assert.NotNil(t, ValidatePrice(option, 0.0126))
+	assert.Nil(t, ValidateAmount(option, 0.1*3))
+	assert.NotNil(t, ValidateAmount(perpetual, 15))
+	assert.NotNil(t, ValidateAmount(perpetual, 0))
+}
+
+func TestMock_AutoRounding(t *testing.T) {
+	server := deribittest.NewServer()
+	defer server.Close()
+
+	client := newMockClient(server)
+	registry := NewInstrumentRegistry(client)
+	registry.setIndex([]models.Instrument{{InstrumentName: "BTC-PERPETUAL", TickSize: 0.5, MinTradeAmount: 10, ExpirationTimestamp: 32503708800000}}, nil)
+	client.SetAutoRounding(registry, RoundPassive)
+
+	params := &models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 25, Price: 6000.7, Type: "limit"}
+	buy, err := client.Buy(params)
+	assert.Nil(t, err)
+	assert.Equal(t, 20.0, buy.Order.Amount)
+	assert.Equal(t, 6000.5, buy.Order.Price.ToFloat64())
+	assert.Equal(t, 6000.7, params.Price, "params unchanged")
+
+	sell, err := client.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 6010.2, Type: "limit"})
+	assert.Nil(t, err)
+	assert.Equal(t, 6010.5, sell.Order.Price.ToFloat64())
+
+	_, err = client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 8, Price: 6000, Type: "limit"})
+	assert.EqualError(t, err, "private/buy: amount 8 of BTC-PERPETUAL rounds to 0, below the amount step 10")
+	assert.Len(t, server.Orders(), 2, "order not sent")
+
+	client.SetAutoRounding(nil, RoundNearest)
+	_, err = client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 5000.3, Type: "limit"})
+	assert.Nil(t, err)
+	order, _ := server.Order("3")
+	assert.Equal(t, 5000.3, order.Price.ToFloat64())
+}
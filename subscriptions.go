@@ -75,6 +75,14 @@ func (c *Client) subscriptionsProcess(event *Event) {
 			return
 		}
 		c.Emit(event.Channel, &notification)
+	} else if strings.HasPrefix(event.Channel, "instrument.state") {
+		var notification models.InstrumentStateNotification
+		err := jsoniter.Unmarshal(event.Data, &notification)
+		if err != nil {
+			log.Printf("%v", err)
+			return
+		}
+		c.Emit(event.Channel, &notification)
 	} else if strings.HasPrefix(event.Channel, "markprice.options") {
 		var notification models.MarkpriceOptionsNotification
 		err := jsoniter.Unmarshal(event.Data, &notification)
@@ -0,0 +1,36 @@
+package deribit
+
+import (
+	"context"
+	"github.com/sourcegraph/jsonrpc2"
+	"strings"
+)
+
+// Transport carries JSONRPC calls to Deribit.
+// Subscriptions are only available over the WebSocket transport,
+// other transports are used for request/response calls only.
+type Transport interface {
+	// Call issues a call and decodes the response into result
+	Call(ctx context.Context, method string, params interface{}, result interface{}) error
+}
+
+// tokenSetter is implemented by transports that send the access token
+// themselves rather than relying on an authenticated connection
+type tokenSetter interface {
+	SetToken(token string)
+}
+
+// rpcTransport is the WebSocket transport, calls are multiplexed over a jsonrpc2.Conn
+type rpcTransport struct {
+	conn *jsonrpc2.Conn
+}
+
+// Call implements Transport
+func (t *rpcTransport) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	return t.conn.Call(ctx, method, params, result)
+}
+
+// isHTTPAddr reports whether addr points to the HTTP API rather than the WebSocket API
+func isHTTPAddr(addr string) bool {
+	return strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://")
+}
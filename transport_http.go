@@ -0,0 +1,125 @@
+package deribit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sourcegraph/jsonrpc2"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// HTTPTransport is a Transport using Deribit's HTTP API,
+// every call is a POST of a JSONRPC request to <addr><method>.
+// Private methods are authorized with the bearer token set by SetToken
+// or, when no token is set, with Basic auth using the API key and secret.
+type HTTPTransport struct {
+	addr       string
+	apiKey     string
+	secretKey  string
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	token string
+	seq   uint64
+}
+
+// NewHTTPTransport creates a HTTPTransport for addr, e.g. RealBaseHTTPURL.
+// httpClient may be nil, in which case http.DefaultClient is used.
+func NewHTTPTransport(addr string, apiKey string, secretKey string, httpClient *http.Client) *HTTPTransport {
+	if !strings.HasSuffix(addr, "/") {
+		addr += "/"
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &HTTPTransport{
+		addr:       addr,
+		apiKey:     apiKey,
+		secretKey:  secretKey,
+		httpClient: httpClient,
+	}
+}
+
+// SetToken sets the bearer token used for private methods
+func (t *HTTPTransport) SetToken(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.token = token
+}
+
+type httpRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      uint64      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type httpResponse struct {
+	Result *json.RawMessage `json:"result"`
+	Error  *jsonrpc2.Error  `json:"error"`
+}
+
+// Call implements Transport
+func (t *HTTPTransport) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(&httpRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&t.seq, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, t.addr+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if strings.HasPrefix(method, "private/") {
+		t.mu.RLock()
+		token := t.token
+		t.mu.RUnlock()
+		if token != "" {
+			req.Header.Set("Authorization", "bearer "+token)
+		} else if t.apiKey != "" {
+			req.SetBasicAuth(t.apiKey, t.secretKey)
+		} else {
+			return ErrAuthenticationIsRequired
+		}
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var r httpResponse
+	if err := json.Unmarshal(data, &r); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("http status %v: %s", resp.StatusCode, data)
+		}
+		return err
+	}
+	if r.Error != nil {
+		return r.Error
+	}
+	if r.Result == nil {
+		return fmt.Errorf("http status %v: missing result", resp.StatusCode)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(*r.Result, result)
+}
@@ -0,0 +1,94 @@
+package deribit
+
+import (
+	"encoding/json"
+	"github.com/frankrap/deribit-api/models"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newHTTPTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, "/api/v2/"+req.Method, r.URL.Path)
+		switch req.Method {
+		case "public/get_time":
+			w.Write([]byte(`{"jsonrpc":"2.0","result":1587560603684}`))
+		case "public/auth":
+			w.Write([]byte(`{"jsonrpc":"2.0","result":{"access_token":"token1","refresh_token":"refresh1"}}`))
+		case "private/get_account_summary":
+			switch r.Header.Get("Authorization") {
+			case "bearer token1":
+				w.Write([]byte(`{"jsonrpc":"2.0","result":{"currency":"BTC","balance":1.5}}`))
+			default:
+				user, secret, _ := r.BasicAuth()
+				if user == "key" && secret == "secret" {
+					w.Write([]byte(`{"jsonrpc":"2.0","result":{"currency":"BTC","balance":2.5}}`))
+					return
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":13009,"message":"unauthorized"}}`))
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"}}`))
+		}
+	}))
+}
+
+func TestHTTPTransport(t *testing.T) {
+	server := newHTTPTestServer(t)
+	defer server.Close()
+
+	client := New(&Configuration{
+		Addr:      server.URL + "/api/v2/",
+		ApiKey:    "key",
+		SecretKey: "secret",
+	})
+	assert.True(t, client.IsConnected())
+
+	tm, err := client.GetTime()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1587560603684), tm)
+
+	summary, err := client.GetAccountSummary(&models.GetAccountSummaryParams{Currency: "BTC"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2.5, summary.Balance)
+
+	err = client.Auth("key", "secret")
+	assert.Nil(t, err)
+	summary, err = client.GetAccountSummary(&models.GetAccountSummaryParams{Currency: "BTC"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1.5, summary.Balance)
+
+	_, err = client.GetCurrencies()
+	assert.NotNil(t, err)
+}
+
+func TestHTTPTransport_Unauthorized(t *testing.T) {
+	server := newHTTPTestServer(t)
+	defer server.Close()
+
+	client := New(&Configuration{
+		Addr: server.URL + "/api/v2/",
+	})
+	_, err := client.GetAccountSummary(&models.GetAccountSummaryParams{Currency: "BTC"})
+	assert.Equal(t, ErrAuthenticationIsRequired, err)
+
+	client = New(&Configuration{
+		Transport: NewHTTPTransport(server.URL+"/api/v2", "key", "wrong", nil),
+	})
+	_, err = client.GetAccountSummary(&models.GetAccountSummaryParams{Currency: "BTC"})
+	assert.NotNil(t, err)
+}